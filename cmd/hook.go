@@ -4,18 +4,20 @@ Copyright © 2025 Cristian Oliveira licence@cristianoliveira.dev
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"io/fs"
-	"os"
-	"strconv"
+	"slices"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
-
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/config"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/marker"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
 )
 
 const (
@@ -26,6 +28,7 @@ const (
 
 func HookCmd(
 	aerospaceClient aerospace.AeroSpaceWMClient,
+	cfg *config.Config,
 ) *cobra.Command {
 	hookCmd := &cobra.Command{
 		Use:   "hook",
@@ -36,15 +39,23 @@ when a program is focused by the launcher (alfred, raycast, etc).
 `,
 	}
 
-	hookCmd.AddCommand(newPullWindowCmd(aerospaceClient))
+	hookCmd.AddCommand(enableOutputFlag(newPullWindowCmd(aerospaceClient, cfg)))
+	hookCmd.AddCommand(enableOutputFlag(newFocusChangedCmd(aerospaceClient, cfg)))
+	hookCmd.AddCommand(enableOutputFlag(newFollowCmd(aerospaceClient, cfg)))
+	hookCmd.AddCommand(compose([]flagsFn{
+		enableOutputFlag,
+		enableFilterFlag,
+		enablePoolFlag,
+	}, newWindowDetectedCmd(aerospaceClient, cfg)))
 
 	return hookCmd
 }
 
 func newPullWindowCmd(
 	aerospaceClient aerospace.AeroSpaceWMClient,
+	cfg *config.Config,
 ) *cobra.Command {
-	return &cobra.Command{
+	command := &cobra.Command{
 		Use:   fmt.Sprintf("%s <previous-workspace> <focused-workspace>", pullWindowSubcommand),
 		Short: "Pull the focused scratchpad window back to the previous workspace",
 		Long: `Pull the focused scratchpad window back to the previous workspace so it behaves like it was summoned there.
@@ -57,65 +68,123 @@ Add this snippet in your aerospace.toml config:
 exec-on-workspace-change = ["/bin/bash", "-c",
   "aerospace-scratchpad hook pull-window $AEROSPACE_PREV_WORKSPACE $AEROSPACE_FOCUSED_WORKSPACE"
 ]
+
+Besides the scratchpad workspaces, any workspace matching a --never-focus pattern
+or the never_focus_workspaces config is handled the same way.
 `,
 		Aliases: []string{"pull"},
 		Args:    cobra.ExactArgs(minArgsPullWindow),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			formatter, err := newOutputFormatter(cmd)
+			if err != nil {
+				return err
+			}
+
+			patterns, err := cmd.Flags().GetStringArray("never-focus")
+			if err != nil {
+				return fmt.Errorf("unable to get never-focus flag: %w", err)
+			}
+			neverFocus, err := aerospace.NewNeverFocusWorkspaces(
+				append(slices.Clone(cfg.NeverFocusWorkspaces), patterns...),
+			)
+			if err != nil {
+				return err
+			}
+
 			handler := newHookHandler(cmd, aerospaceClient)
-			return handler.handlePullWindow(args[0], args[1])
+			handler.neverFocus = neverFocus
+			events, runErr := handler.handlePullWindow(args[0], args[1])
+			return writeResult(cmd, formatter, handler.finish(events, runErr), runErr)
 		},
 	}
+
+	command.Flags().StringArray(
+		"never-focus", []string{},
+		`Regex of a hidden workspace to pull windows out of, besides the scratchpads.
+Can be used multiple times.`,
+	)
+
+	return command
 }
 
 type hookHandler struct {
-	cmd    *cobra.Command
-	client aerospace.AeroSpaceWMClient
-	logger logger.Logger
+	ctx        context.Context
+	cmd        *cobra.Command
+	client     *aerospace.AeroSpaceClient
+	logger     logger.Logger
+	start      time.Time
+	neverFocus *aerospace.NeverFocusWorkspaces
+	autoHide   autoHide
+	// perWorkspace stashes windows in the scratchpad of their workspace
+	perWorkspace bool
 }
 
 func newHookHandler(
 	cmd *cobra.Command,
 	client aerospace.AeroSpaceWMClient,
 ) *hookHandler {
+	aerospaceClient := aerospace.NewAeroSpaceClient(client)
+	dry, _ := cmd.Flags().GetBool("dry-run")
+	aerospaceClient.SetOptions(aerospace.ClientOpts{
+		DryRun: dry,
+	})
+
 	return &hookHandler{
+		ctx:    cmd.Context(),
 		cmd:    cmd,
-		client: client,
+		client: aerospaceClient,
 		logger: logger.GetDefaultLogger(),
+		start:  time.Now(),
+	}
+}
+
+// perWorkspace reports whether per-workspace scratchpads are enabled by the
+// global flag or the config.
+func perWorkspace(cmd *cobra.Command, cfg *config.Config) bool {
+	if cmd.Flags().Changed("per-workspace") {
+		enabled, _ := cmd.Flags().GetBool("per-workspace")
+		return enabled
 	}
+	return cfg.PerWorkspace
+}
+
+// finish completes the events of the hook, see scratchpad.FinishEvents.
+func (h *hookHandler) finish(events []cli.OutputEvent, err error) []cli.OutputEvent {
+	return scratchpad.FinishEvents(h.client, "hook", h.start, events, err)
 }
 
 func (h *hookHandler) handlePullWindow(
 	prevWorkspace string,
 	focusedWorkspace string,
-) error {
+) ([]cli.OutputEvent, error) {
 	h.logger.LogInfo(
 		"HOOK: pull-window invoked",
 		"previous-workspace", prevWorkspace,
 		"focused-workspace", focusedWorkspace,
 	)
 
-	if prevWorkspace == constants.DefaultScratchpadWorkspaceName {
+	if h.neverFocus.Match(prevWorkspace) {
 		h.logger.LogDebug(
-			"HOOK: previous workspace is scratchpad, nothing to do",
+			"HOOK: previous workspace is hidden, nothing to do",
 			"workspace", prevWorkspace,
 		)
-		return nil
+		return nil, nil
 	}
 
-	if focusedWorkspace != constants.DefaultScratchpadWorkspaceName {
+	if !h.neverFocus.Match(focusedWorkspace) {
 		h.logger.LogDebug(
-			"HOOK: focused workspace is not scratchpad",
+			"HOOK: focused workspace is not hidden",
 			"workspace", focusedWorkspace,
 		)
-		return nil
+		return nil, nil
 	}
 
-	h.logger.LogInfo("HOOK: focused workspace is scratchpad")
+	h.logger.LogInfo("HOOK: focused workspace is hidden", "workspace", focusedWorkspace)
 
-	focusedWindow, err := h.client.Windows().GetFocusedWindow()
+	focusedWindow, err := h.client.GetFocusedWindow(h.ctx)
 	if err != nil {
-		return h.fail(
-			"Error: unable to get focused window",
+		return nil, h.fail(
+			"unable to get focused window",
 			err,
 			"HOOK: unable to get focused window",
 		)
@@ -123,30 +192,30 @@ func (h *hookHandler) handlePullWindow(
 
 	h.logger.LogInfo("HOOK: focused window", "window", focusedWindow)
 
-	if focusedWindow.Workspace != constants.DefaultScratchpadWorkspaceName {
+	if focusedWindow.Workspace != focusedWorkspace {
 		h.logger.LogDebug(
-			"HOOK: focused window is no longer in scratchpad, skipping move",
+			"HOOK: focused window is no longer in the hidden workspace, skipping move",
 			"workspace", focusedWindow.Workspace,
 		)
-		return nil
+		return nil, nil
 	}
 
 	cleared, markerErr := h.clearMovingMarker()
 	if markerErr != nil {
-		return h.fail(
-			"Error: unable to remove temp file",
+		return nil, h.fail(
+			"unable to clear moving marker",
 			markerErr,
-			"HOOK: unable to remove temp file",
+			"HOOK: unable to clear moving marker",
 		)
 	}
 
 	if cleared {
-		h.logger.LogInfo("HOOK: temp file exists, returning")
-		return nil
+		h.logger.LogInfo("HOOK: moving marker is active, workspace change caused by a command")
+		return nil, nil
 	}
 
 	if moveErr := h.moveWindowToWorkspace(focusedWindow.WindowID, prevWorkspace); moveErr != nil {
-		return moveErr
+		return nil, moveErr
 	}
 
 	h.logger.LogInfo(
@@ -155,63 +224,51 @@ func (h *hookHandler) handlePullWindow(
 		"window", focusedWindow,
 	)
 
-	return nil
+	pool := aerospace.PoolOf(focusedWindow.Workspace)
+	event := cli.OutputEvent{
+		Command:         "hook",
+		Action:          "to-workspace",
+		Workspace:       focusedWindow.Workspace,
+		TargetWorkspace: prevWorkspace,
+		Result:          "ok",
+		Pool:            pool,
+	}.WithWindow(*focusedWindow)
+
+	return []cli.OutputEvent{event}, nil
 }
 
+// clearMovingMarker reports whether a command is moving windows right now,
+// consuming its marker. Stale markers are removed and ignored.
 func (h *hookHandler) clearMovingMarker() (bool, error) {
-	_, err := os.Stat(constants.TempScratchpadMovingFile)
-	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return false, nil
-		}
-		return false, err
-	}
-
-	if removeErr := os.Remove(constants.TempScratchpadMovingFile); removeErr != nil {
-		return true, removeErr
+	moving := marker.New()
+	if h.client.IsDryRun() {
+		// Leave the marker for the real run
+		return moving.Active()
 	}
 
-	return true, nil
+	return moving.Consume()
 }
 
 func (h *hookHandler) moveWindowToWorkspace(windowID int, workspace string) error {
-	client := h.client.Connection()
-
-	response, err := client.SendCommand(
-		"move-node-to-workspace",
-		[]string{
-			workspace,
-			"--window-id", strconv.Itoa(windowID),
-			"--focus-follows-window",
-		},
-	)
+	err := h.client.MoveWindowToWorkspaceAndFocus(h.ctx, windowID, workspace)
 	if err != nil {
 		return h.fail(
-			fmt.Sprintf("Error: unable to move window %d to workspace %s", windowID, workspace),
+			fmt.Sprintf("unable to move window %d to workspace %s", windowID, workspace),
 			err,
 			"HOOK: unable to move window to workspace",
 		)
 	}
 
-	if response.ExitCode != 0 {
-		return h.fail(
-			fmt.Sprintf("Error: unable to move window %d to workspace %s", windowID, workspace),
-			errors.New(response.StdErr),
-			"HOOK: unable to move window to workspace - non-zero exit",
-		)
-	}
-
 	return nil
 }
 
+// fail logs the failure and returns the error reported to the user by cobra.
 func (h *hookHandler) fail(userMessage string, err error, logMessage string) error {
 	if err != nil {
 		h.logger.LogError(logMessage, "error", err)
-		h.cmd.PrintErrf("%s: %v\n", userMessage, err)
 		return fmt.Errorf("%s: %w", userMessage, err)
 	}
 
 	h.logger.LogError(logMessage)
-	h.cmd.PrintErrln(userMessage)
 	return errors.New(userMessage)
 }
@@ -0,0 +1,312 @@
+/*
+Copyright © 2025 Cristian Oliveira licence@cristianoliveira.dev
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/spf13/cobra"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/config"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/marker"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+)
+
+const focusChangedSubcommand = "focus-changed"
+
+// autoHide selects the floating windows the focus-changed hook sends back to
+// the scratchpad.
+type autoHide struct {
+	all         bool
+	patterns    []*regexp.Regexp
+	gracePeriod time.Duration
+}
+
+// enabled reports whether any window can be hidden.
+func (a autoHide) enabled() bool {
+	return a.all || len(a.patterns) > 0
+}
+
+// matches reports whether the window is hidden when losing the focus.
+func (a autoHide) matches(window windows.Window) bool {
+	if a.all {
+		return true
+	}
+	return slices.ContainsFunc(a.patterns, func(pattern *regexp.Regexp) bool {
+		return pattern.MatchString(window.AppName)
+	})
+}
+
+func newFocusChangedCmd(
+	aerospaceClient aerospace.AeroSpaceWMClient,
+	cfg *config.Config,
+) *cobra.Command {
+	command := &cobra.Command{
+		Use:   fmt.Sprintf("%s <window-id>", focusChangedSubcommand),
+		Short: "Hide floating scratchpad windows that lost the focus",
+		Long: `Hide floating scratchpad windows that lost the focus, so they behave like a drop-down terminal.
+
+Nothing is hidden unless auto-hide is enabled with --auto-hide, --auto-hide-all
+or the auto_hide config. The hook waits for the grace period and skips when the
+focus moved again in the meantime, so quick round trips don't make windows flicker.
+
+Only the windows brought out of a scratchpad or pool by show, summon or next
+are hidden, back to where they came from. Other floating windows are left alone.
+
+This is usually hooked via on-focus-changed.
+
+Add this snippet in your aerospace.toml config:
+
+'''toml
+on-focus-changed = ["exec-and-forget /bin/bash -c 'aerospace-scratchpad hook focus-changed $(aerospace list-windows --focused --format %{window-id})'"]
+'''
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formatter, err := newOutputFormatter(cmd)
+			if err != nil {
+				return err
+			}
+
+			windowID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid window id '%s': %w", args[0], err)
+			}
+
+			options, err := newAutoHide(cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			handler := newHookHandler(cmd, aerospaceClient)
+			handler.autoHide = options
+			events, runErr := handler.handleFocusChanged(windowID)
+			return writeResult(cmd, formatter, handler.finish(events, runErr), runErr)
+		},
+	}
+
+	command.Flags().Bool("auto-hide-all", false, "Hide every floating window losing the focus")
+	command.Flags().StringArray(
+		"auto-hide", []string{},
+		`Regex of the app name of the windows to hide when losing the focus.
+Can be used multiple times.`,
+	)
+	command.Flags().Duration(
+		"grace-period", config.DefaultGracePeriod,
+		"Wait this long before hiding windows (default from config)",
+	)
+
+	return command
+}
+
+// newAutoHide merges the auto-hide flags with the config.
+func newAutoHide(cmd *cobra.Command, cfg *config.Config) (autoHide, error) {
+	all, err := cmd.Flags().GetBool("auto-hide-all")
+	if err != nil {
+		return autoHide{}, fmt.Errorf("unable to get auto-hide-all flag: %w", err)
+	}
+	patterns, err := cmd.Flags().GetStringArray("auto-hide")
+	if err != nil {
+		return autoHide{}, fmt.Errorf("unable to get auto-hide flag: %w", err)
+	}
+
+	options := autoHide{
+		all:         all || cfg.AutoHide.All,
+		gracePeriod: cfg.AutoHide.GracePeriod.Duration(),
+	}
+	for _, pattern := range append(slices.Clone(cfg.AutoHide.Patterns), patterns...) {
+		compiled, compileErr := regexp.Compile(pattern)
+		if compileErr != nil {
+			return autoHide{}, fmt.Errorf("invalid auto-hide pattern '%s': %w", pattern, compileErr)
+		}
+		options.patterns = append(options.patterns, compiled)
+	}
+
+	if cmd.Flags().Changed("grace-period") {
+		options.gracePeriod, _ = cmd.Flags().GetDuration("grace-period")
+	}
+
+	return options, nil
+}
+
+func (h *hookHandler) handleFocusChanged(windowID int) ([]cli.OutputEvent, error) {
+	h.logger.LogInfo("HOOK: focus-changed invoked", "window-id", windowID)
+
+	if !h.autoHide.enabled() {
+		h.logger.LogDebug("HOOK: auto-hide is disabled, nothing to do")
+		return nil, nil
+	}
+
+	if err := h.wait(h.autoHide.gracePeriod); err != nil {
+		return nil, h.fail("unable to wait for the grace period", err, "HOOK: grace period interrupted")
+	}
+
+	focusedWindow, err := h.client.GetFocusedWindow(h.ctx)
+	if err != nil {
+		return nil, h.fail(
+			"unable to get focused window",
+			err,
+			"HOOK: unable to get focused window",
+		)
+	}
+
+	if focusedWindow.WindowID != windowID {
+		h.logger.LogDebug(
+			"HOOK: focus changed again during the grace period, skipping",
+			"window-id", windowID,
+			"focused-window-id", focusedWindow.WindowID,
+		)
+		return nil, nil
+	}
+
+	if aerospace.IsScratchpadWorkspace(focusedWindow.Workspace) {
+		h.logger.LogDebug(
+			"HOOK: focused window is stashed, left to pull-window",
+			"workspace", focusedWindow.Workspace,
+		)
+		return nil, nil
+	}
+
+	// Commands showing several windows focus only one of them
+	moving, err := marker.New().Active()
+	if err != nil {
+		return nil, h.fail(
+			"unable to read moving marker",
+			err,
+			"HOOK: unable to read moving marker",
+		)
+	}
+	if moving {
+		h.logger.LogInfo("HOOK: moving marker is active, focus change caused by a command")
+		return nil, nil
+	}
+
+	store := state.New()
+	current, err := store.Load()
+	if err != nil {
+		return nil, h.fail("unable to load state", err, "HOOK: unable to load state")
+	}
+	if len(current.ShownFrom) == 0 {
+		h.logger.LogDebug("HOOK: no window shown from a scratchpad, nothing to do")
+		return nil, nil
+	}
+
+	wsWindows, err := h.client.GetAllWindowsByWorkspace(h.ctx, focusedWindow.Workspace)
+	if err != nil {
+		return nil, h.fail(
+			fmt.Sprintf("unable to get windows of workspace %s", focusedWindow.Workspace),
+			err,
+			"HOOK: unable to get workspace windows",
+		)
+	}
+
+	// Back to the scratchpad or pool each window was shown from
+	mover := aerospace.NewAeroSpaceMover(h.client)
+	mover.SetScratchpadResolver(func(window windows.Window) string {
+		return current.ShownFrom[window.WindowID]
+	})
+
+	var events []cli.OutputEvent
+	defer func() {
+		h.forgetHidden(store, events)
+	}()
+	for _, window := range wsWindows {
+		if !h.shouldAutoHide(window, *focusedWindow, current.ShownFrom) {
+			continue
+		}
+
+		scratchpad := mover.ScratchpadFor(window)
+		pool := aerospace.PoolOf(scratchpad)
+		event := cli.OutputEvent{
+			Command:         "hook",
+			Action:          "to-scratchpad",
+			Workspace:       window.Workspace,
+			TargetWorkspace: scratchpad,
+			Result:          "ok",
+			Pool:            pool,
+		}.WithWindow(window)
+
+		moveErr := mover.MoveWindowToScratchpad(h.ctx, window)
+		if errors.Is(moveErr, aerospace.ErrAlreadyInWorkspace) {
+			continue
+		}
+		if moveErr != nil {
+			event.Result = "error"
+			event.Message = moveErr.Error()
+			return append(events, event), h.fail(
+				fmt.Sprintf("unable to move window %d to scratchpad", window.WindowID),
+				moveErr,
+				"HOOK: unable to auto-hide window",
+			)
+		}
+
+		h.logger.LogInfo("HOOK: auto-hid window", "window", window, "scratchpad", scratchpad)
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// shouldAutoHide reports whether the window lost the focus to the focused one
+// and is hidden because of it. Only the windows shown from a scratchpad are,
+// and windows of the focused app are kept, they are usually its dialogs.
+func (h *hookHandler) shouldAutoHide(
+	window windows.Window,
+	focused windows.Window,
+	shownFrom map[int]string,
+) bool {
+	return shownFrom[window.WindowID] != "" &&
+		window.WindowLayout == "floating" &&
+		window.WindowID != focused.WindowID &&
+		window.AppName != focused.AppName &&
+		h.autoHide.matches(window)
+}
+
+// forgetHidden forgets the hidden windows in the state, as stashing them with
+// a command does: they no longer follow the focused workspace. Failing to do
+// so never fails the hook.
+func (h *hookHandler) forgetHidden(store *state.Store, events []cli.OutputEvent) {
+	if h.client.IsDryRun() || len(events) == 0 {
+		return
+	}
+
+	err := store.Update(func(st *state.State) {
+		for _, event := range events {
+			if event.Result != "ok" {
+				continue
+			}
+			st.RemoveSticky(event.WindowID)
+			st.ForgetPreviousFocus(event.WindowID)
+			st.ForgetShownFrom(event.WindowID)
+		}
+	})
+	if err != nil {
+		h.logger.LogError("HOOK: unable to save state", "error", err)
+	}
+}
+
+// wait sleeps for the given duration unless the command is cancelled.
+func (h *hookHandler) wait(duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-h.ctx.Done():
+		return h.ctx.Err()
+	}
+}
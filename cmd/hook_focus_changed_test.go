@@ -0,0 +1,347 @@
+package cmd_test
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/layout"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-scratchpad/cmd"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/marker"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
+)
+
+func TestHookFocusChanged(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	focusedWindow := &windows.Window{
+		WindowID:     1,
+		AppName:      "Finder",
+		Workspace:    "ws1",
+		WindowLayout: "h_tiles",
+	}
+	wsWindows := []windows.Window{
+		*focusedWindow,
+		{WindowID: 2, AppName: "Terminal", Workspace: "ws1", WindowLayout: "floating"},
+		// Dialog of the focused app
+		{WindowID: 3, AppName: "Finder", Workspace: "ws1", WindowLayout: "floating"},
+		{WindowID: 4, AppName: "Notes", Workspace: "ws1", WindowLayout: "floating"},
+		{WindowID: 5, AppName: "Terminal", Workspace: "ws1", WindowLayout: "v_tiles"},
+	}
+
+	// Records the windows as shown from a scratchpad by a command
+	recordShown := func(t *testing.T, shownFrom map[int]string) {
+		t.Helper()
+
+		err := state.New().Update(func(st *state.State) {
+			for windowID, scratchpad := range shownFrom {
+				st.RecordShownFrom(windowID, scratchpad)
+				st.AddSticky(windowID)
+				st.SetPreviousFocus(windowID, focusedWindow.WindowID)
+			}
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expectHide := func(mockClient *testutils.MockAeroSpaceWM, windowID int, scratchpad string) {
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(
+				workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: scratchpad},
+				workspaces.MoveWindowToWorkspaceOpts{WindowID: &windowID},
+			).
+			Return(nil).
+			Times(1)
+		mockClient.GetLayoutMock().EXPECT().
+			SetLayout(
+				[]string{"floating"},
+				layout.SetLayoutOpts{WindowID: &windowID},
+			).
+			Return(nil).
+			Times(1)
+	}
+
+	t.Run("does nothing unless auto-hide is enabled", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// No expectations, the hook must not talk to AeroSpace
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		if _, err := testutils.CmdExecute(rootCmd, "hook", "focus-changed", "1"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("hides the matching floating windows that lost the focus", func(t *testing.T) {
+		isolateMarker(t)
+		recordShown(t, map[int]string{
+			2: constants.DefaultScratchpadWorkspaceName,
+			4: constants.DefaultScratchpadWorkspaceName,
+		})
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(focusedWindow, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindowsByWorkspace("ws1").
+			Return(wsWindows, nil).
+			Times(1)
+		expectHide(mockClient, 2, constants.DefaultScratchpadWorkspaceName)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		out, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "focus-changed", "1",
+			"--auto-hide", "^Terminal$",
+			"--grace-period", "0",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		expected := "command=hook action=to-scratchpad window_id=2 app_name=Terminal workspace=ws1 " +
+			"target_workspace=.scratchpad result=ok message=\"\" pool=\"\" monitor=\"\" " +
+			"window_title=\"\" app_bundle_id=\"\" window_layout=floating parent_layout=\"\" duration_ms=0 schema_version=2\n"
+		if out != expected {
+			t.Fatalf("expected event %q, got %q", expected, out)
+		}
+
+		current, err := state.New().Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(current.Sticky, []int{4}) ||
+			!maps.Equal(current.PreviousFocus, map[int]int{4: focusedWindow.WindowID}) ||
+			!maps.Equal(current.ShownFrom, map[int]string{4: constants.DefaultScratchpadWorkspaceName}) {
+			t.Fatalf("expected only the hidden window to be forgotten, got %+v", current)
+		}
+	})
+
+	t.Run("hides every floating window from the config to where it was shown from", func(t *testing.T) {
+		isolateMarker(t)
+		recordShown(t, map[int]string{
+			2: constants.DefaultScratchpadWorkspaceName,
+			4: ".scratchpad-pool-media",
+		})
+
+		path := filepath.Join(t.TempDir(), "config.json")
+		content := []byte(`{"auto_hide": {"all": true, "grace_period": "0s"}}`)
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		t.Setenv(constants.EnvAeroSpaceScratchpadConfig, path)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(focusedWindow, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindowsByWorkspace("ws1").
+			Return(wsWindows, nil).
+			Times(1)
+		expectHide(mockClient, 2, constants.DefaultScratchpadWorkspaceName)
+		expectHide(mockClient, 4, ".scratchpad-pool-media")
+
+		rootCmd := cmd.RootCmd(mockClient)
+		if _, err := testutils.CmdExecute(rootCmd, "hook", "focus-changed", "1"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("leaves the floating windows not shown from a scratchpad alone", func(t *testing.T) {
+		isolateMarker(t)
+		recordShown(t, map[int]string{2: constants.DefaultScratchpadWorkspaceName})
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(focusedWindow, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindowsByWorkspace("ws1").
+			Return(wsWindows, nil).
+			Times(1)
+		// The plain floating Notes window stays
+		expectHide(mockClient, 2, constants.DefaultScratchpadWorkspaceName)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "focus-changed", "1",
+			"--auto-hide-all",
+			"--grace-period", "0",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("does nothing without windows shown from a scratchpad", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(focusedWindow, nil).
+			Times(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "focus-changed", "1",
+			"--auto-hide-all",
+			"--grace-period", "0",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("skips when the focus changed during the grace period", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(&windows.Window{WindowID: 2, AppName: "Terminal", Workspace: "ws1"}, nil).
+			Times(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "focus-changed", "1",
+			"--auto-hide-all",
+			"--grace-period", "1ms",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("skips when the moving marker is active", func(t *testing.T) {
+		isolateMarker(t)
+
+		if err := marker.New().Create(); err != nil {
+			t.Fatalf("failed to create marker: %v", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(focusedWindow, nil).
+			Times(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "focus-changed", "1",
+			"--auto-hide-all",
+			"--grace-period", "0",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		// Left for pull-window to consume
+		if active, _ := marker.New().Active(); !active {
+			t.Fatalf("expected the marker to be kept")
+		}
+	})
+
+	t.Run("plans the hide without moving windows in dry-run", func(t *testing.T) {
+		isolateMarker(t)
+		recordShown(t, map[int]string{4: ".scratchpad-ws-ws1"})
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(focusedWindow, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindowsByWorkspace("ws1").
+			Return(wsWindows, nil).
+			Times(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		out, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "focus-changed", "1",
+			"--auto-hide", "^Notes$",
+			"--grace-period", "0",
+			"--dry-run",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		expected := "command=hook action=to-scratchpad window_id=4 app_name=Notes workspace=ws1 " +
+			"target_workspace=.scratchpad-ws-ws1 result=planned message=\"\" pool=\"\" monitor=\"\" " +
+			"window_title=\"\" app_bundle_id=\"\" window_layout=floating parent_layout=\"\" duration_ms=0 schema_version=2\n" +
+			"command=hook action=layout window_id=4 app_name=Notes workspace=ws1 " +
+			"target_workspace=\"\" result=planned message=floating pool=\"\" monitor=\"\" " +
+			"window_title=\"\" app_bundle_id=\"\" window_layout=floating parent_layout=\"\" duration_ms=0 schema_version=2\n"
+		if out != expected {
+			t.Fatalf("expected planned events %q, got %q", expected, out)
+		}
+
+		current, err := state.New().Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if current.ShownFrom[4] != ".scratchpad-ws-ws1" {
+			t.Fatalf("expected the state to be kept in dry-run, got %+v", current)
+		}
+	})
+
+	t.Run("fails on an invalid window id", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		if _, err := testutils.CmdExecute(rootCmd, "hook", "focus-changed", "abc"); err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 Cristian Oliveira licence@cristianoliveira.dev
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/spf13/cobra"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/config"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/marker"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+)
+
+const (
+	followSubcommand = "follow"
+
+	argsFollow = 2
+)
+
+func newFollowCmd(
+	aerospaceClient aerospace.AeroSpaceWMClient,
+	cfg *config.Config,
+) *cobra.Command {
+	command := &cobra.Command{
+		Use:   fmt.Sprintf("%s <previous-workspace> <focused-workspace>", followSubcommand),
+		Short: "Carry the windows shown by show along to the focused workspace",
+		Long: `Carry the sticky windows along to the focused workspace, like i3 scratchpad windows.
+
+Windows brought by show are sticky until they are hidden, closed or tiled, unless
+"sticky": false is set in the config file.
+
+This is usually hooked via exec-on-workspace-change, before pull-window.
+
+Add this snippet in your aerospace.toml config:
+
+'''toml
+exec-on-workspace-change = ["/bin/bash", "-c",
+  "aerospace-scratchpad hook follow $AEROSPACE_PREV_WORKSPACE $AEROSPACE_FOCUSED_WORKSPACE; aerospace-scratchpad hook pull-window $AEROSPACE_PREV_WORKSPACE $AEROSPACE_FOCUSED_WORKSPACE"
+]
+'''
+`,
+		Args: cobra.ExactArgs(argsFollow),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formatter, err := newOutputFormatter(cmd)
+			if err != nil {
+				return err
+			}
+
+			neverFocus, err := aerospace.NewNeverFocusWorkspaces(cfg.NeverFocusWorkspaces)
+			if err != nil {
+				return err
+			}
+
+			handler := newHookHandler(cmd, aerospaceClient)
+			handler.neverFocus = neverFocus
+			events, runErr := handler.handleFollow(args[0], args[1])
+			return writeResult(cmd, formatter, handler.finish(events, runErr), runErr)
+		},
+	}
+
+	return command
+}
+
+func (h *hookHandler) handleFollow(
+	prevWorkspace string,
+	focusedWorkspace string,
+) ([]cli.OutputEvent, error) {
+	h.logger.LogInfo(
+		"HOOK: follow invoked",
+		"previous-workspace", prevWorkspace,
+		"focused-workspace", focusedWorkspace,
+	)
+
+	if h.neverFocus.Match(focusedWorkspace) {
+		h.logger.LogDebug(
+			"HOOK: focused workspace is hidden, left to pull-window",
+			"workspace", focusedWorkspace,
+		)
+		return nil, nil
+	}
+
+	// Left for pull-window to consume
+	moving, err := marker.New().Active()
+	if err != nil {
+		return nil, h.fail(
+			"unable to read moving marker",
+			err,
+			"HOOK: unable to read moving marker",
+		)
+	}
+	if moving {
+		h.logger.LogInfo("HOOK: moving marker is active, workspace change caused by a command")
+		return nil, nil
+	}
+
+	store := state.New()
+	current, err := store.Load()
+	if err != nil {
+		return nil, h.fail("unable to load state", err, "HOOK: unable to load state")
+	}
+	if len(current.Sticky) == 0 {
+		h.logger.LogDebug("HOOK: no sticky windows, nothing to do")
+		return nil, nil
+	}
+
+	allWindows, err := h.client.GetAllWindows(h.ctx)
+	if err != nil {
+		return nil, h.fail("unable to get windows", err, "HOOK: unable to get windows")
+	}
+
+	mover := aerospace.NewAeroSpaceMover(h.client)
+	target := &workspaces.Workspace{Workspace: focusedWorkspace}
+	var sticky []int
+	var events []cli.OutputEvent
+	var moveErr error
+	for _, window := range allWindows {
+		if !slices.Contains(current.Sticky, window.WindowID) {
+			continue
+		}
+
+		// Hidden or tiled since shown, it is not sticky anymore
+		if window.WindowLayout != "floating" ||
+			aerospace.IsScratchpadWorkspace(window.Workspace) {
+			h.logger.LogDebug("HOOK: window is no longer sticky", "window", window)
+			continue
+		}
+		sticky = append(sticky, window.WindowID)
+
+		event := cli.OutputEvent{
+			Command:         "hook",
+			Action:          "to-workspace",
+			Workspace:       window.Workspace,
+			TargetWorkspace: focusedWorkspace,
+			Result:          "ok",
+		}.WithWindow(window)
+
+		err = mover.MoveWindowToWorkspace(h.ctx, &window, target, false)
+		if errors.Is(err, aerospace.ErrAlreadyInWorkspace) {
+			continue
+		}
+		if err != nil {
+			event.Result = "error"
+			event.Message = err.Error()
+			events = append(events, event)
+			moveErr = errors.Join(moveErr, h.fail(
+				fmt.Sprintf("unable to move window %d to workspace %s", window.WindowID, focusedWorkspace),
+				err,
+				"HOOK: unable to carry sticky window",
+			))
+			continue
+		}
+
+		h.logger.LogInfo("HOOK: carried sticky window", "window", window, "workspace", focusedWorkspace)
+		events = append(events, event)
+	}
+
+	// Closed windows are forgotten too, the rest of the state is kept
+	if !h.client.IsDryRun() {
+		if err = store.Update(func(st *state.State) {
+			st.Sticky = sticky
+		}); err != nil {
+			h.logger.LogError("HOOK: unable to save state", "error", err)
+		}
+	}
+
+	return events, moveErr
+}
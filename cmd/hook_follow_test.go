@@ -0,0 +1,219 @@
+package cmd_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-scratchpad/cmd"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/marker"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
+)
+
+func TestHookFollow(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	allWindows := []windows.Window{
+		{WindowID: 1, AppName: "Terminal", Workspace: "ws1", WindowLayout: "floating"},
+		// Tiled since shown
+		{WindowID: 2, AppName: "Notes", Workspace: "ws1", WindowLayout: "h_tiles"},
+		// Hidden since shown
+		{WindowID: 3, AppName: "Spotify", Workspace: ".scratchpad-pool-media", WindowLayout: "floating"},
+		{WindowID: 4, AppName: "Finder", Workspace: "ws1", WindowLayout: "floating"},
+		{WindowID: 5, AppName: "Calculator", Workspace: "ws2", WindowLayout: "floating"},
+	}
+
+	remember := func(t *testing.T, windowIDs ...int) {
+		t.Helper()
+
+		if err := state.New().Save(state.State{Sticky: windowIDs}); err != nil {
+			t.Fatalf("failed to save state: %v", err)
+		}
+	}
+
+	t.Run("carries the sticky windows to the focused workspace", func(t *testing.T) {
+		isolateMarker(t)
+		// Window 6 was closed since shown
+		remember(t, 1, 2, 3, 5, 6)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(allWindows, nil).
+			Times(1)
+		windowID := 1
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(
+				workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws2"},
+				workspaces.MoveWindowToWorkspaceOpts{WindowID: &windowID},
+			).
+			Return(nil).
+			Times(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		out, err := testutils.CmdExecute(rootCmd, "hook", "follow", "ws1", "ws2")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		expected := "command=hook action=to-workspace window_id=1 app_name=Terminal workspace=ws1 " +
+			"target_workspace=ws2 result=ok message=\"\" pool=\"\" monitor=\"\" " +
+			"window_title=\"\" app_bundle_id=\"\" window_layout=floating parent_layout=\"\" duration_ms=0 schema_version=2\n"
+		if out != expected {
+			t.Fatalf("expected event %q, got %q", expected, out)
+		}
+
+		current, err := state.New().Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(current.Sticky, []int{1, 5}) {
+			t.Fatalf("expected only windows 1 and 5 to stay sticky, got %v", current.Sticky)
+		}
+	})
+
+	t.Run("keeps the rest of the state", func(t *testing.T) {
+		isolateMarker(t)
+		stashedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		if err := state.New().Update(func(st *state.State) {
+			st.AddSticky(1)
+			st.SetPreviousFocus(1, 4)
+			st.RecordStashed(3, "ws1", stashedAt)
+		}); err != nil {
+			t.Fatalf("failed to save state: %v", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(allWindows, nil).
+			Times(1)
+		windowID := 1
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(
+				workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws2"},
+				workspaces.MoveWindowToWorkspaceOpts{WindowID: &windowID},
+			).
+			Return(nil).
+			Times(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		if _, err := testutils.CmdExecute(rootCmd, "hook", "follow", "ws1", "ws2"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		current, err := state.New().Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if current.PreviousFocus[1] != 4 {
+			t.Errorf("expected the previous focus to be kept, got %v", current.PreviousFocus)
+		}
+		if record := current.Stash[3]; record.Origin != "ws1" || !record.StashedAt.Equal(stashedAt) {
+			t.Errorf("expected the stashed window to be kept, got %v", current.Stash)
+		}
+	})
+
+	t.Run("does nothing without sticky windows", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// No expectations, the hook must not talk to AeroSpace
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		if _, err := testutils.CmdExecute(rootCmd, "hook", "follow", "ws1", "ws2"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("leaves hidden workspaces to pull-window", func(t *testing.T) {
+		isolateMarker(t)
+		remember(t, 1)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "follow", "ws1", constants.DefaultScratchpadWorkspaceName,
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("skips when the moving marker is active", func(t *testing.T) {
+		isolateMarker(t)
+		remember(t, 1)
+
+		if err := marker.New().Create(); err != nil {
+			t.Fatalf("failed to create marker: %v", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		if _, err := testutils.CmdExecute(rootCmd, "hook", "follow", "ws1", "ws2"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		// Left for pull-window to consume
+		if active, _ := marker.New().Active(); !active {
+			t.Fatalf("expected the marker to be kept")
+		}
+	})
+
+	t.Run("plans the moves and keeps the state in dry-run", func(t *testing.T) {
+		isolateMarker(t)
+		remember(t, 1, 6)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(allWindows, nil).
+			Times(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		out, err := testutils.CmdExecute(rootCmd, "hook", "follow", "ws1", "ws2", "--dry-run")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		expected := "command=hook action=to-workspace window_id=1 app_name=Terminal workspace=ws1 " +
+			"target_workspace=ws2 result=planned message=\"\" pool=\"\" monitor=\"\" " +
+			"window_title=\"\" app_bundle_id=\"\" window_layout=floating parent_layout=\"\" duration_ms=0 schema_version=2\n"
+		if out != expected {
+			t.Fatalf("expected planned event %q, got %q", expected, out)
+		}
+
+		current, _ := state.New().Load()
+		if !slices.Equal(current.Sticky, []int{1, 6}) {
+			t.Fatalf("expected the state to be kept, got %v", current.Sticky)
+		}
+	})
+}
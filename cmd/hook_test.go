@@ -3,7 +3,9 @@ package cmd_test
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"go.uber.org/mock/gomock"
 
@@ -13,23 +15,22 @@ import (
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/marker"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
 )
 
-func cleanupMarkerFile(t *testing.T) {
+// isolateMarker gives the test its own moving marker location.
+func isolateMarker(t *testing.T) {
 	t.Helper()
 
-	err := os.Remove(constants.TempScratchpadMovingFile)
-	if err != nil && !os.IsNotExist(err) {
-		t.Fatalf("failed to clean marker file: %v", err)
-	}
+	t.Setenv("TMPDIR", t.TempDir())
 }
 
 func TestHookPullWindow(t *testing.T) {
 	logger.SetDefaultLogger(&logger.EmptyLogger{})
 
 	t.Run("moves focused scratchpad window to previous workspace", func(t *testing.T) {
-		cleanupMarkerFile(t)
+		isolateMarker(t)
 
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -76,8 +77,137 @@ func TestHookPullWindow(t *testing.T) {
 		}
 	})
 
+	t.Run("moves focused window out of a per-workspace scratchpad", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		focusedWindow := &windows.Window{
+			WindowID:  99,
+			Workspace: ".scratchpad-ws-ws1",
+		}
+
+		gomock.InOrder(
+			mockClient.GetWindowsMock().
+				EXPECT().
+				GetFocusedWindow().
+				Return(focusedWindow, nil).
+				Times(1),
+			mockClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{
+						WorkspaceName: "prev-ws",
+					},
+					workspaces.MoveWindowToWorkspaceOpts{
+						WindowID: &focusedWindow.WindowID,
+					},
+				).
+				Return(nil).
+				Times(1),
+		)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, err := testutils.CmdExecute(
+			rootCmd,
+			"hook",
+			"pull-window",
+			"prev-ws",
+			".scratchpad-ws-ws1",
+		)
+
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("moves focused window out of a never-focus workspace", func(t *testing.T) {
+		isolateMarker(t)
+
+		for name, setup := range map[string]func(t *testing.T) []string{
+			"from the flag": func(_ *testing.T) []string {
+				return []string{"--never-focus", "^stash$"}
+			},
+			"from the config": func(t *testing.T) []string {
+				path := filepath.Join(t.TempDir(), "config.json")
+				content := []byte(`{"never_focus_workspaces": ["^stash$"]}`)
+				if err := os.WriteFile(path, content, 0o600); err != nil {
+					t.Fatalf("failed to write config: %v", err)
+				}
+				t.Setenv(constants.EnvAeroSpaceScratchpadConfig, path)
+				return nil
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+				focusedWindow := &windows.Window{
+					WindowID:  99,
+					Workspace: "stash",
+				}
+
+				gomock.InOrder(
+					mockClient.GetWindowsMock().
+						EXPECT().
+						GetFocusedWindow().
+						Return(focusedWindow, nil).
+						Times(1),
+					mockClient.GetWorkspacesMock().EXPECT().
+						MoveWindowToWorkspaceWithOpts(
+							workspaces.MoveWindowToWorkspaceArgs{
+								WorkspaceName: "prev-ws",
+							},
+							workspaces.MoveWindowToWorkspaceOpts{
+								WindowID: &focusedWindow.WindowID,
+							},
+						).
+						Return(nil).
+						Times(1),
+				)
+
+				args := append(
+					[]string{"hook", "pull-window", "prev-ws", "stash"},
+					setup(t)...,
+				)
+				rootCmd := cmd.RootCmd(mockClient)
+				if _, err := testutils.CmdExecute(rootCmd, args...); err != nil {
+					t.Fatalf("expected success, got error %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("ignores workspaces not matching any never-focus pattern", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// No expectations, the hook must not talk to AeroSpace
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, err := testutils.CmdExecute(
+			rootCmd,
+			"hook",
+			"pull-window",
+			"prev-ws",
+			"stash-2",
+			"--never-focus",
+			"^stash$",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
 	t.Run("skips when previous workspace is scratchpad", func(t *testing.T) {
-		cleanupMarkerFile(t)
+		isolateMarker(t)
 
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -98,16 +228,12 @@ func TestHookPullWindow(t *testing.T) {
 		}
 	})
 
-	t.Run("skips move when marker file exists", func(t *testing.T) {
-		cleanupMarkerFile(t)
+	t.Run("skips move when the moving marker is active", func(t *testing.T) {
+		isolateMarker(t)
 
-		err := os.WriteFile(constants.TempScratchpadMovingFile, []byte("moving"), 0o600)
-		if err != nil {
-			t.Fatalf("failed to create marker file: %v", err)
+		if err := marker.New().Create(); err != nil {
+			t.Fatalf("failed to create marker: %v", err)
 		}
-		t.Cleanup(func() {
-			cleanupMarkerFile(t)
-		})
 
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -137,10 +263,108 @@ func TestHookPullWindow(t *testing.T) {
 		}
 	})
 
+	t.Run("pulls the window when the moving marker expired", func(t *testing.T) {
+		isolateMarker(t)
+
+		stale := marker.NewAt(marker.DefaultPath(), -time.Second)
+		if err := stale.Create(); err != nil {
+			t.Fatalf("failed to create marker: %v", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		focusedWindow := &windows.Window{
+			WindowID:  124,
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+		}
+
+		gomock.InOrder(
+			mockClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(focusedWindow, nil).
+				Times(1),
+			mockClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(1),
+		)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, execErr := testutils.CmdExecute(
+			rootCmd,
+			"hook",
+			"pull-window",
+			"prev-ws",
+			constants.DefaultScratchpadWorkspaceName,
+		)
+		if execErr != nil {
+			t.Fatalf("expected success, got error %v", execErr)
+		}
+
+		if _, exists, _ := stale.Read(); exists {
+			t.Fatalf("expected the stale marker to be removed")
+		}
+	})
+
+	t.Run("moving commands silence the hook", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		scratchpadWindow := windows.Window{
+			WindowID:  124,
+			AppName:   "Finder",
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+		}
+
+		// next brings the window, the hook run right after must not move it
+		mockClient.GetWorkspacesMock().EXPECT().
+			GetFocusedWorkspace().
+			Return(&workspaces.Workspace{Workspace: "ws1"}, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindowsByWorkspace(constants.DefaultScratchpadWorkspaceName).
+			Return([]windows.Window{scratchpadWindow}, nil).
+			Times(1)
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+		mockClient.GetFocusMock().EXPECT().
+			SetFocusByWindowID(scratchpadWindow.WindowID).
+			Return(nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(&scratchpadWindow, nil).
+			Times(1)
+
+		if _, err := testutils.CmdExecute(cmd.RootCmd(mockClient), "next"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		_, err := testutils.CmdExecute(
+			cmd.RootCmd(mockClient),
+			"hook",
+			"pull-window",
+			"prev-ws",
+			constants.DefaultScratchpadWorkspaceName,
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
 	t.Run(
 		"fails when getting focused window returns an error",
 		func(t *testing.T) {
-			cleanupMarkerFile(t)
+			isolateMarker(t)
 
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
@@ -171,7 +395,7 @@ func TestHookPullWindow(t *testing.T) {
 	t.Run(
 		"fails when moving window returns an error",
 		func(t *testing.T) {
-			cleanupMarkerFile(t)
+			isolateMarker(t)
 
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
@@ -218,4 +442,48 @@ func TestHookPullWindow(t *testing.T) {
 			}
 		},
 	)
+
+	t.Run("plans the move without moving the window in dry-run", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		focusedWindow := &windows.Window{
+			WindowID:  99,
+			AppName:   "Finder",
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+		}
+
+		mockClient.GetWindowsMock().
+			EXPECT().
+			GetFocusedWindow().
+			Return(focusedWindow, nil).
+			Times(1)
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+			Times(0)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		out, err := testutils.CmdExecute(
+			rootCmd,
+			"hook",
+			"pull-window",
+			"prev-ws",
+			constants.DefaultScratchpadWorkspaceName,
+			"--dry-run",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		expected := "command=hook action=to-workspace window_id=99 app_name=Finder workspace=.scratchpad " +
+			"target_workspace=prev-ws result=planned message=focus-follows-window pool=\"\" monitor=\"\" " +
+			"window_title=\"\" app_bundle_id=\"\" window_layout=\"\" parent_layout=\"\" duration_ms=0 schema_version=2\n"
+		if out != expected {
+			t.Fatalf("expected planned event %q, got %q", expected, out)
+		}
+	})
 }
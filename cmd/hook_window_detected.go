@@ -0,0 +1,231 @@
+/*
+Copyright © 2025 Cristian Oliveira licence@cristianoliveira.dev
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/spf13/cobra"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/config"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
+)
+
+const windowDetectedSubcommand = "window-detected"
+
+// stashRule is a config.StashRule with its filters parsed.
+type stashRule struct {
+	filters []aerospace.Filter
+	pool    string
+}
+
+func newWindowDetectedCmd(
+	aerospaceClient aerospace.AeroSpaceWMClient,
+	cfg *config.Config,
+) *cobra.Command {
+	command := &cobra.Command{
+		Use:   fmt.Sprintf("%s [window-id]", windowDetectedSubcommand),
+		Short: "Stash new windows matching a stash rule",
+		Long: `Stash new windows matching a stash rule, so apps like password managers or music players are born stashed.
+
+The window is moved to the scratchpad, or the pool of the rule, and made floating.
+Rules come from --filter (with --pool) and the stash_rules config, the first match wins.
+The window defaults to the focused one, which is the new window when it opens.
+
+This is usually hooked via on-window-detected.
+
+Add this snippet in your aerospace.toml config:
+
+'''toml
+[[on-window-detected]]
+check-further-callbacks = true
+run = ["exec-and-forget aerospace-scratchpad hook window-detected"]
+'''
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formatter, err := newOutputFormatter(cmd)
+			if err != nil {
+				return err
+			}
+
+			windowID := 0
+			if len(args) > 0 {
+				windowID, err = strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid window id '%s': %w", args[0], err)
+				}
+			}
+
+			rules, err := newStashRules(cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			handler := newHookHandler(cmd, aerospaceClient)
+			handler.perWorkspace = perWorkspace(cmd, cfg)
+			events, runErr := handler.handleWindowDetected(windowID, rules)
+			return writeResult(cmd, formatter, handler.finish(events, runErr), runErr)
+		},
+	}
+
+	return command
+}
+
+// newStashRules returns the rule of the flags, if any, followed by the rules
+// of the config.
+func newStashRules(cmd *cobra.Command, cfg *config.Config) ([]stashRule, error) {
+	filterFlags, err := cmd.Flags().GetStringArray("filter")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get filter flags: %w", err)
+	}
+	pool, err := cmd.Flags().GetString("pool")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get pool flag: %w", err)
+	}
+	if len(filterFlags) == 0 && pool != "" {
+		return nil, errors.New("--pool requires at least one --filter")
+	}
+
+	var rules []stashRule
+	if len(filterFlags) > 0 {
+		filters, parseErr := aerospace.ParseFilters(filterFlags)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		rules = append(rules, stashRule{filters: filters, pool: pool})
+	}
+
+	for _, rule := range cfg.StashRules {
+		filters, parseErr := aerospace.ParseFilters(rule.Filters())
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		rules = append(rules, stashRule{filters: filters, pool: rule.Pool})
+	}
+
+	for _, rule := range rules {
+		if poolErr := scratchpad.ValidatePool(rule.pool); poolErr != nil {
+			return nil, poolErr
+		}
+	}
+
+	return rules, nil
+}
+
+func (h *hookHandler) handleWindowDetected(
+	windowID int,
+	rules []stashRule,
+) ([]cli.OutputEvent, error) {
+	h.logger.LogInfo("HOOK: window-detected invoked", "window-id", windowID)
+
+	if len(rules) == 0 {
+		h.logger.LogDebug("HOOK: no stash rules, nothing to do")
+		return nil, nil
+	}
+
+	window, err := h.detectedWindow(windowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if aerospace.IsScratchpadWorkspace(window.Workspace) {
+		h.logger.LogDebug("HOOK: window is already stashed", "window", window)
+		return nil, nil
+	}
+
+	rule, matched, err := matchStashRule(*window, rules)
+	if err != nil {
+		return nil, h.fail("unable to match stash rules", err, "HOOK: unable to match stash rules")
+	}
+	if !matched {
+		h.logger.LogDebug("HOOK: window matches no stash rule", "window", window)
+		return nil, nil
+	}
+
+	mover := aerospace.NewAeroSpaceMover(h.client)
+	switch {
+	case rule.pool != "":
+		mover.SetScratchpadResolver(aerospace.PoolScratchpad(rule.pool))
+	case h.perWorkspace:
+		mover.SetScratchpadResolver(aerospace.PerWorkspaceScratchpad)
+	}
+
+	scratchpadWorkspace := mover.ScratchpadFor(*window)
+	pool := aerospace.PoolOf(scratchpadWorkspace)
+	event := cli.OutputEvent{
+		Command:         "hook",
+		Action:          "to-scratchpad",
+		Workspace:       window.Workspace,
+		TargetWorkspace: scratchpadWorkspace,
+		Result:          "ok",
+		Pool:            pool,
+	}.WithWindow(*window)
+
+	if moveErr := mover.MoveWindowToScratchpad(h.ctx, *window); moveErr != nil {
+		event.Result = "error"
+		event.Message = moveErr.Error()
+		return []cli.OutputEvent{event}, h.fail(
+			fmt.Sprintf("unable to move window %d to scratchpad", window.WindowID),
+			moveErr,
+			"HOOK: unable to stash detected window",
+		)
+	}
+
+	h.logger.LogInfo("HOOK: stashed detected window", "window", window, "scratchpad", scratchpadWorkspace)
+
+	return []cli.OutputEvent{event}, nil
+}
+
+// detectedWindow returns the window with the given ID, or the focused window
+// when the ID is 0.
+func (h *hookHandler) detectedWindow(windowID int) (*windows.Window, error) {
+	if windowID == 0 {
+		window, err := h.client.GetFocusedWindow(h.ctx)
+		if err != nil {
+			return nil, h.fail(
+				"unable to get focused window",
+				err,
+				"HOOK: unable to get focused window",
+			)
+		}
+		return window, nil
+	}
+
+	allWindows, err := h.client.GetAllWindows(h.ctx)
+	if err != nil {
+		return nil, h.fail("unable to get windows", err, "HOOK: unable to get windows")
+	}
+	for _, window := range allWindows {
+		if window.WindowID == windowID {
+			return &window, nil
+		}
+	}
+
+	return nil, h.fail(
+		fmt.Sprintf("window %d not found", windowID),
+		nil,
+		"HOOK: detected window not found",
+	)
+}
+
+// matchStashRule returns the first rule matching the window.
+func matchStashRule(window windows.Window, rules []stashRule) (stashRule, bool, error) {
+	for _, rule := range rules {
+		matched, err := aerospace.ApplyFilters(window, rule.filters)
+		if err != nil {
+			return stashRule{}, false, err
+		}
+		if matched {
+			return rule, true, nil
+		}
+	}
+
+	return stashRule{}, false, nil
+}
@@ -0,0 +1,243 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/layout"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-scratchpad/cmd"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
+)
+
+func TestHookWindowDetected(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	newWindow := windows.Window{
+		WindowID:     7,
+		AppName:      "1Password",
+		AppBundleID:  "com.1password.1password",
+		WindowTitle:  "Vault",
+		Workspace:    "ws1",
+		WindowLayout: "h_tiles",
+	}
+	allWindows := []windows.Window{
+		{WindowID: 1, AppName: "Finder", Workspace: "ws1", WindowLayout: "h_tiles"},
+		newWindow,
+	}
+
+	expectStash := func(mockClient *testutils.MockAeroSpaceWM, windowID int, workspace string) {
+		gomock.InOrder(
+			mockClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: workspace},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &windowID},
+				).
+				Return(nil).
+				Times(1),
+			mockClient.GetLayoutMock().EXPECT().
+				SetLayout(
+					[]string{"floating"},
+					layout.SetLayoutOpts{WindowID: &windowID},
+				).
+				Return(nil).
+				Times(1),
+		)
+	}
+
+	writeRules := func(t *testing.T, content string) {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		t.Setenv(constants.EnvAeroSpaceScratchpadConfig, path)
+	}
+
+	t.Run("does nothing without stash rules", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// No expectations, the hook must not talk to AeroSpace
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		if _, err := testutils.CmdExecute(rootCmd, "hook", "window-detected", "7"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("stashes the window matching the flag rule in its pool", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(allWindows, nil).
+			Times(1)
+		expectStash(mockClient, 7, ".scratchpad-pool-secrets")
+
+		rootCmd := cmd.RootCmd(mockClient)
+		out, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "window-detected", "7",
+			"--filter", "app-name=^1Password$",
+			"--pool", "secrets",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		expected := "command=hook action=to-scratchpad window_id=7 app_name=1Password workspace=ws1 " +
+			"target_workspace=.scratchpad-pool-secrets result=ok message=\"\" pool=secrets monitor=\"\" " +
+			"window_title=Vault app_bundle_id=com.1password.1password window_layout=h_tiles parent_layout=\"\" duration_ms=0 schema_version=2\n"
+		if out != expected {
+			t.Fatalf("expected event %q, got %q", expected, out)
+		}
+	})
+
+	t.Run("stashes the focused window matching a config rule", func(t *testing.T) {
+		writeRules(t, `{"stash_rules": [
+			{"app_name": "^Spotify$", "pool": "media"},
+			{"app_bundle_id": "^com\\.1password\\.", "window_title": "^Vault$"}
+		]}`)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(&newWindow, nil).
+			Times(1)
+		expectStash(mockClient, 7, constants.DefaultScratchpadWorkspaceName)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		if _, err := testutils.CmdExecute(rootCmd, "hook", "window-detected"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("runs the on_hide script of the config", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "hidden")
+		writeRules(t, `{
+			"stash_rules": [{"app_name": "^1Password$"}],
+			"scripts": {"on_hide": "echo $SCRATCHPAD_WINDOW_ID > `+out+`"}
+		}`)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(&newWindow, nil).
+			Times(1)
+		expectStash(mockClient, 7, constants.DefaultScratchpadWorkspaceName)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		if _, err := testutils.CmdExecute(rootCmd, "hook", "window-detected"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		// The script runs detached, after the command returned
+		data := testutils.ReadFileEventually(t, out, 5*time.Second)
+		if strings.TrimSpace(string(data)) != "7" {
+			t.Fatalf("expected the script to get window 7, got %q", data)
+		}
+	})
+
+	t.Run("keeps windows matching no rule", func(t *testing.T) {
+		writeRules(t, `{"stash_rules": [{"app_name": "^Spotify$"}]}`)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(allWindows, nil).
+			Times(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		out, err := testutils.CmdExecute(rootCmd, "hook", "window-detected", "7")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if out != "" {
+			t.Fatalf("expected no events, got %q", out)
+		}
+	})
+
+	t.Run("keeps windows already stashed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stashed := newWindow
+		stashed.Workspace = ".scratchpad-pool-media"
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(&stashed, nil).
+			Times(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "window-detected",
+			"--filter", "app-name=^1Password$",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("fails when the window does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(allWindows, nil).
+			Times(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "window-detected", "42",
+			"--filter", "app-name=^1Password$",
+		)
+		if err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+	})
+
+	t.Run("fails on a pool without filters", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		_, err := testutils.CmdExecute(
+			rootCmd,
+			"hook", "window-detected", "7",
+			"--pool", "secrets",
+		)
+		if err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+	})
+}
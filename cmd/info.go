@@ -12,9 +12,13 @@ import (
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
 )
 
+// annotationOffline marks the commands that run without AeroSpace, they
+// connect on their own when they can use it.
+const annotationOffline = "offline"
+
 // InfoCmd represents the info command.
 func InfoCmd(
-	aerospace aerospace.AeroSpaceWMClient,
+	aerospaceClient aerospace.AeroSpaceWMClient,
 ) *cobra.Command {
 	infoCmd := &cobra.Command{
 		Use:   "info",
@@ -24,8 +28,18 @@ func InfoCmd(
 Checks the compatibility of the installed version of Aerospace with the current version of aerospace-scratchpad.
 As well as other relevant information.
 `,
+		Annotations: map[string]string{annotationOffline: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			socketClient := aerospace.Connection()
+			if client, ok := aerospaceClient.(*aerospace.AeroSpaceClient); ok {
+				// Report AeroSpace as missing instead of waiting for it
+				err := client.Connect(cmd.Context(), aerospace.RetryPolicy{Attempts: 1})
+				if err != nil {
+					printInfo(cmd, "unknown", "unknown", "Unknown. Reason: "+err.Error())
+					return nil
+				}
+			}
+
+			socketClient := aerospaceClient.Connection()
 			var validationInfo string
 			if err := socketClient.CheckServerVersion(); err != nil {
 				validationInfo = "Incompatible. Reason: " + err.Error()
@@ -43,7 +57,18 @@ As well as other relevant information.
 				return fmt.Errorf("failed to get server version: %w", err)
 			}
 
-			cmd.Println(fmt.Sprintf(`Aerospace Scratchpad
+			printInfo(cmd, serverVersion, socketPath, validationInfo)
+
+			return nil
+		},
+	}
+
+	return infoCmd
+}
+
+// printInfo prints the info report.
+func printInfo(cmd *cobra.Command, serverVersion, socketPath, status string) {
+	cmd.Println(fmt.Sprintf(`Aerospace Scratchpad
 
 [Aerospace]
 Version: %s
@@ -55,15 +80,9 @@ Workspace: %s
 [Compatibility]
 Status: %s
 			`,
-				serverVersion,
-				socketPath,
-				constants.DefaultScratchpadWorkspaceName,
-				validationInfo,
-			))
-
-			return nil
-		},
-	}
-
-	return infoCmd
+		serverVersion,
+		socketPath,
+		constants.DefaultScratchpadWorkspaceName,
+		status,
+	))
 }
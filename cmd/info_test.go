@@ -14,6 +14,7 @@ import (
 	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
 	"github.com/cristianoliveira/aerospace-ipc/pkg/client"
 	"github.com/cristianoliveira/aerospace-scratchpad/cmd"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
 	client_mock "github.com/cristianoliveira/aerospace-scratchpad/internal/mocks/client"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
 )
@@ -144,4 +145,26 @@ func TestInfoCmd(t *testing.T) {
 		cmdAsString := "aerospace-scratchpad " + strings.Join(args, " ")
 		testutils.MatchSnapshot(t, nil, cmdAsString, output.String(), err)
 	})
+
+	t.Run("reports AeroSpace as missing when it is not running", func(t *testing.T) {
+		aerospaceClient := aerospace.NewAeroSpaceClient(nil)
+		aerospaceClient.SetDialer(func() (aerospace.AeroSpaceWMClient, error) {
+			return nil, errors.New("connection refused")
+		})
+
+		args := []string{"info"}
+		command := cmd.RootCmd(aerospaceClient)
+		command.SetArgs(args)
+		output := &bytes.Buffer{}
+		command.SetOut(output)
+		command.SetErr(output)
+
+		err := command.Execute()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		cmdAsString := "aerospace-scratchpad " + strings.Join(args, " ")
+		testutils.MatchSnapshot(t, nil, cmdAsString, output.String(), err)
+	})
 }
@@ -4,20 +4,15 @@ Copyright © 2025 Cristian Oliveira license@cristianoliveira.dev
 package cmd
 
 import (
-	"os"
-	"sort"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
-	windowsipc "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/stderr"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
 )
 
 // ListCmd represents the list command.
-func ListCmd(aerospaceClient *aerospace.AeroSpaceClient) *cobra.Command {
+func ListCmd(sp *scratchpad.Scratchpad) *cobra.Command {
 	command := &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"ls"},
@@ -26,134 +21,45 @@ func ListCmd(aerospaceClient *aerospace.AeroSpaceClient) *cobra.Command {
 
 A scratchpad window is defined as:
 - A window in the .scratchpad workspace, OR
+- A window in a per-workspace scratchpad (e.g. .scratchpad-ws-ws1), OR
+- A window in a scratchpad pool (e.g. .scratchpad-pool-media), OR
 - A floating window (WindowLayout == "floating")
 
 The output is scriptable and supports multiple formats (text, json, tsv, csv).
 `,
-		Run: func(cmd *cobra.Command, args []string) {
-			runListCommand(cmd, args, aerospaceClient)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formatter, err := newOutputFormatter(cmd)
+			if err != nil {
+				return err
+			}
+
+			filterFlags, err := cmd.Flags().GetStringArray("filter")
+			if err != nil {
+				return fmt.Errorf("unable to get filter flags: %w", err)
+			}
+
+			groupBy, err := cmd.Flags().GetString("group-by")
+			if err != nil {
+				return fmt.Errorf("unable to get group-by flag: %w", err)
+			}
+			if groupBy != "" && groupBy != "workspace" {
+				return fmt.Errorf("unsupported group-by: %s", groupBy)
+			}
+
+			events, runErr := sp.List(cmd.Context(), scratchpad.ListOptions{
+				Selector: scratchpad.Selector{
+					Filters: filterFlags,
+				},
+				GroupByWorkspace: groupBy == "workspace",
+			})
+			return writeResult(cmd, formatter, events, runErr)
 		},
 	}
 
-	return command
-}
-
-func runListCommand(cmd *cobra.Command, args []string, aerospaceClient *aerospace.AeroSpaceClient) {
-	logger := logger.GetDefaultLogger()
-	logger.LogDebug("LIST: start command", "args", args)
-
-	formatter, err := getOutputFormatter(cmd)
-	if err != nil {
-		return
-	}
-
-	filterFlags, err := cmd.Flags().GetStringArray("filter")
-	if err != nil {
-		logger.LogError("LIST: unable to get filter flags", "error", err)
-		stderr.Println("Error: unable to get filter flags")
-		return
-	}
-
-	querier := aerospace.NewAerospaceQuerier(aerospaceClient.GetUnderlyingClient())
-	scratchpadWindows, err := querier.GetScratchpadWindows()
-	if err != nil {
-		logger.LogError("LIST: unable to get scratchpad windows", "error", err)
-		stderr.Printf("Error: %v\n", err)
-		return
-	}
+	command.Flags().String(
+		"group-by", "",
+		"Group windows by owning workspace: workspace",
+	)
 
-	logger.LogDebug("LIST: retrieved scratchpad windows", "count", len(scratchpadWindows))
-
-	filteredWindows := applyFiltersToList(scratchpadWindows, filterFlags)
-	sortWindowsByAppName(filteredWindows)
-	outputWindows(formatter, filteredWindows)
-}
-
-func getOutputFormatter(cmd *cobra.Command) (*cli.OutputFormatter, error) {
-	logger := logger.GetDefaultLogger()
-	outputFormat, err := cmd.Flags().GetString("output")
-	if err != nil {
-		logger.LogError("LIST: unable to get output flag", "error", err)
-		stderr.Println("Error: unable to get output format")
-		return nil, err
-	}
-
-	formatter, err := cli.NewOutputFormatter(os.Stdout, outputFormat)
-	if err != nil {
-		logger.LogError("LIST: invalid output format", "error", err)
-		stderr.Println("Error: unsupported output format")
-		return nil, err
-	}
-
-	return formatter, nil
-}
-
-func applyFiltersToList(
-	scratchpadWindows []windowsipc.Window,
-	filterFlags []string,
-) []windowsipc.Window {
-	if len(filterFlags) == 0 {
-		return scratchpadWindows
-	}
-
-	filters, err := aerospace.ParseFilters(filterFlags)
-	if err != nil {
-		stderr.Printf("Error: %v\n", err)
-		return []windowsipc.Window{}
-	}
-
-	var filteredWindows []windowsipc.Window
-	for _, window := range scratchpadWindows {
-		matches, applyErr := aerospace.ApplyFilters(window, filters)
-		if applyErr != nil {
-			stderr.Printf("Error: %v\n", applyErr)
-			return []windowsipc.Window{}
-		}
-		if matches {
-			filteredWindows = append(filteredWindows, window)
-		}
-	}
-
-	return filteredWindows
-}
-
-func sortWindowsByAppName(windows []windowsipc.Window) {
-	sort.Slice(windows, func(i, j int) bool {
-		// Sort by app name first
-		if windows[i].AppName != windows[j].AppName {
-			return windows[i].AppName < windows[j].AppName
-		}
-		// If app names are equal, sort by window ID for stable ordering
-		return windows[i].WindowID < windows[j].WindowID
-	})
-}
-
-func outputWindows(formatter *cli.OutputFormatter, windows []windowsipc.Window) {
-	logger := logger.GetDefaultLogger()
-
-	if len(windows) == 0 {
-		if printErr := formatter.Print(cli.OutputEvent{
-			Command:   "list",
-			Action:    "list",
-			Result:    "none",
-			Message:   "no scratchpad windows found",
-			Workspace: "",
-		}); printErr != nil {
-			logger.LogError("LIST: unable to write output", "error", printErr)
-		}
-		return
-	}
-
-	for _, window := range windows {
-		if printErr := formatter.Print(cli.OutputEvent{
-			Command:   "list",
-			Action:    "list",
-			WindowID:  window.WindowID,
-			AppName:   window.AppName,
-			Workspace: window.Workspace,
-			Result:    "ok",
-		}); printErr != nil {
-			logger.LogError("LIST: unable to write output", "error", printErr)
-		}
-	}
+	return command
 }
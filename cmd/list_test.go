@@ -13,13 +13,11 @@ import (
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/stderr"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
 )
 
 func TestListCmd(t *testing.T) { //nolint:gocognit
 	logger.SetDefaultLogger(&logger.EmptyLogger{})
-	stderr.SetBehavior(false)
 
 	t.Run("lists scratchpad windows from workspace", func(t *testing.T) {
 		command := "list"
@@ -211,6 +209,65 @@ func TestListCmd(t *testing.T) { //nolint:gocognit
 		testutils.MatchSnapshot(t, tree, cmdAsString, out, err)
 	})
 
+	t.Run("lists the pool of each scratchpad window", func(t *testing.T) {
+		command := "list"
+		args := []string{command, "--output", "tsv"}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tree := []testutils.AeroSpaceTree{
+			{
+				Windows: []windows.Window{
+					{
+						AppName:   "Spotify",
+						WindowID:  1111,
+						Workspace: ".scratchpad-pool-media",
+					},
+				},
+				Workspace: &workspaces.Workspace{
+					Workspace: ".scratchpad-pool-media",
+				},
+			},
+			{
+				Windows: []windows.Window{
+					{
+						AppName:   "Terminal",
+						WindowID:  2222,
+						Workspace: constants.DefaultScratchpadWorkspaceName,
+					},
+				},
+				Workspace: &workspaces.Workspace{
+					Workspace: constants.DefaultScratchpadWorkspaceName,
+				},
+			},
+		}
+
+		allWindows := testutils.ExtractAllWindows(tree)
+		scratchpadWindows := testutils.ExtractScratchpadWindows(tree)
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return(allWindows, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace(constants.DefaultScratchpadWorkspaceName).
+				Return(scratchpadWindows.Windows, nil).
+				Times(1),
+		)
+
+		cmd := cmd.RootCmd(aerospaceClient)
+		out, err := testutils.CmdExecute(cmd, args...)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		cmdAsString := "aerospace-scratchpad " + strings.Join(args, " ")
+		testutils.MatchSnapshot(t, tree, cmdAsString, out, err)
+	})
+
 	t.Run("lists scratchpad windows in json format", func(t *testing.T) {
 		command := "list"
 		args := []string{command, "--output", "json"}
@@ -262,6 +319,65 @@ func TestListCmd(t *testing.T) { //nolint:gocognit
 		testutils.MatchSnapshot(t, tree, cmdAsString, out, err)
 	})
 
+	t.Run("tells apart the windows of the same app in json format", func(t *testing.T) {
+		command := "list"
+		args := []string{command, "--output", "json"}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tree := []testutils.AeroSpaceTree{
+			{
+				Windows: []windows.Window{
+					{
+						AppName:                     "Terminal",
+						AppBundleID:                 "com.apple.Terminal",
+						WindowID:                    1111,
+						WindowTitle:                 "~/work",
+						WindowLayout:                "floating",
+						WindowParentContainerLayout: "floating",
+						Workspace:                   constants.DefaultScratchpadWorkspaceName,
+					},
+					{
+						AppName:                     "Terminal",
+						AppBundleID:                 "com.apple.Terminal",
+						WindowID:                    2222,
+						WindowTitle:                 "~/notes",
+						WindowLayout:                "floating",
+						WindowParentContainerLayout: "floating",
+						Workspace:                   constants.DefaultScratchpadWorkspaceName,
+					},
+				},
+				Workspace: &workspaces.Workspace{
+					Workspace: constants.DefaultScratchpadWorkspaceName,
+				},
+			},
+		}
+
+		allWindows := testutils.ExtractAllWindows(tree)
+		scratchpadWindows := testutils.ExtractScratchpadWindows(tree)
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return(allWindows, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace(constants.DefaultScratchpadWorkspaceName).
+				Return(scratchpadWindows.Windows, nil).
+				Times(1),
+		)
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), args...)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		cmdAsString := "aerospace-scratchpad " + strings.Join(args, " ")
+		testutils.MatchSnapshot(t, tree, cmdAsString, out, err)
+	})
+
 	t.Run("returns empty result when no scratchpad windows", func(t *testing.T) {
 		command := "list"
 		args := []string{command}
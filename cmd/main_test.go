@@ -0,0 +1,31 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+)
+
+func TestMain(m *testing.M) {
+	// Moving commands create the moving marker, keep it out of the real
+	// temp directory
+	dir, err := os.MkdirTemp("", "aerospace-scratchpad-test")
+	if err != nil {
+		panic(err)
+	}
+	if err = os.Setenv("TMPDIR", dir); err != nil {
+		panic(err)
+	}
+	// Never load the configuration of the developer running the tests, e.g.
+	// their per_workspace default or their scripts
+	if err = os.Setenv(constants.EnvAeroSpaceScratchpadConfig, filepath.Join(dir, "no-config.json")); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
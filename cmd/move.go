@@ -4,25 +4,15 @@ Copyright © 2025 Cristian Oliveira license@cristianoliveira.dev
 package cmd
 
 import (
-	"errors"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 
-	windowsipc "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/stderr"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
 )
 
 // MoveCmd represents the move command.
-//
-//nolint:funlen,gocognit
-func MoveCmd(aerospaceClient *aerospace.AeroSpaceClient) *cobra.Command {
+func MoveCmd(sp *scratchpad.Scratchpad) *cobra.Command {
 	command := &cobra.Command{
 		Use:   "move <pattern>",
 		Short: "Move a window to scratchpad",
@@ -34,198 +24,40 @@ If no pattern is provided, it moves the currently focused window.
 To move all windows that match the focused window's app name to the scratchpad, use the --all-matching flag.
 To move all floating windows (scratchpad windows) to the scratchpad, use the --all-floating flag.
 `,
-		Run: func(cmd *cobra.Command, args []string) {
-			logger := logger.GetDefaultLogger()
-			logger.LogDebug("MOVE: start command", "args", args)
-
-			outputFormat, err := cmd.Flags().GetString("output")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formatter, err := newOutputFormatter(cmd)
 			if err != nil {
-				logger.LogError("MOVE: unable to get output flag", "error", err)
-				stderr.Println("Error: unable to get output format")
-				return
+				return err
 			}
 
-			formatter, err := cli.NewOutputFormatter(os.Stdout, outputFormat)
-			if err != nil {
-				logger.LogError("MOVE: invalid output format", "error", err)
-				stderr.Println("Error: unsupported output format")
-				return
+			opts := scratchpad.MoveOptions{}
+			if len(args) > 0 {
+				opts.Pattern = args[0]
 			}
 
-			// Get all-floating flag first to determine behavior
-			allFloatingFlag, err := cmd.Flags().GetBool("all-floating")
+			opts.AllFloating, err = cmd.Flags().GetBool("all-floating")
 			if err != nil {
-				logger.LogError(
-					"MOVE: unable to get all-floating flag",
-					"error",
-					err,
-				)
-				stderr.Println("Error: unable to get all-floating flag")
-				return
-			}
-
-			var windowNamePattern string
-			focusedWindowID := -1
-
-			// Skip pattern logic when --all-floating is used
-			if !allFloatingFlag {
-				windowNamePattern, focusedWindowID, err = getWindowPattern(
-					args,
-					aerospaceClient,
-					logger,
-				)
-				if err != nil {
-					return
-				}
+				return fmt.Errorf("unable to get all-floating flag: %w", err)
 			}
 
-			// Parse filter flags (matches show command behavior)
-			filterFlags, err := cmd.Flags().GetStringArray("filter")
+			opts.AllMatching, err = cmd.Flags().GetBool("all-matching")
 			if err != nil {
-				logger.LogError(
-					"MOVE: unable to get filter flags",
-					"error",
-					err,
-				)
-				stderr.Println("Error: unable to get filter flags")
-				return
+				return fmt.Errorf("unable to get all-matching flag: %w", err)
 			}
 
-			// Get all-matching flag
-			allMatchingFlag, err := cmd.Flags().GetBool("all-matching")
+			opts.Filters, err = cmd.Flags().GetStringArray("filter")
 			if err != nil {
-				logger.LogError(
-					"MOVE: unable to get all-matching flag",
-					"error",
-					err,
-				)
-				stderr.Println("Error: unable to get all-matching flag")
-				return
-			}
-
-			// Query windows matching pattern and filters
-			querier := aerospace.NewAerospaceQuerier(aerospaceClient.GetUnderlyingClient())
-			mover := aerospace.NewAeroSpaceMover(aerospaceClient)
-
-			var windows []windowsipc.Window
-			if allFloatingFlag {
-				// Get all floating windows when --all-floating is set
-				logger.LogDebug("MOVE: using --all-floating flag, getting all floating windows")
-				windows, err = querier.GetAllFloatingWindows()
-				if err != nil {
-					logger.LogError(
-						"MOVE: error retrieving floating windows",
-						"error", err,
-					)
-					stderr.Println("Error: %v", err)
-					return
-				}
-			} else {
-				// Normal pattern-based filtering
-				windows, err = querier.GetFilteredWindows(
-					windowNamePattern,
-					filterFlags,
-				)
-				if err != nil {
-					logger.LogError(
-						"MOVE: error retrieving filtered windows",
-						"error", err,
-						"pattern", windowNamePattern,
-						"filterFlags", filterFlags,
-					)
-					stderr.Println("Error: %v", err)
-					return
-				}
-			}
-
-			logger.LogDebug(
-				"MOVE: retrieved filtered windows",
-				"windows", windows,
-				"filterFlags", filterFlags,
-			)
-
-			logger.LogDebug(
-				"SHOW: first window to hide, will focus next tiling window after hiding",
-			)
-			if err = aerospaceClient.FocusNextTilingWindow(); err != nil {
-				// No need to exit here, just log the error and continue
-				logger.LogError(
-					"SHOW: unable to focus next tiling window",
-					"error",
-					err,
-				)
+				return fmt.Errorf("unable to get filter flags: %w", err)
 			}
 
-			// When using --all-floating, skip the focused window check
-			if allFloatingFlag && len(windows) == 0 {
-				if printErr := formatter.Print(cli.OutputEvent{
-					Command:   "move",
-					Action:    "to-scratchpad",
-					Result:    "none",
-					Message:   "no floating windows found",
-					Workspace: "",
-				}); printErr != nil {
-					logger.LogError("MOVE: unable to write output", "error", printErr)
-				}
-				return
+			opts.Pool, err = cmd.Flags().GetString("pool")
+			if err != nil {
+				return fmt.Errorf("unable to get pool flag: %w", err)
 			}
 
-			for _, window := range windows {
-				// Skip non-focused windows unless the --all-matching or --all-floating flag is provided
-				if !allFloatingFlag && focusedWindowID != -1 &&
-					window.WindowID != focusedWindowID &&
-					!allMatchingFlag {
-					logger.LogDebug(
-						"MOVE: skipping window, not focused and --all-matching flag not provided",
-						"window", window,
-						"focusedWindowId", focusedWindowID,
-					)
-					continue
-				}
-
-				moveErr := mover.MoveWindowToScratchpad(window)
-				if moveErr != nil {
-					if strings.Contains(
-						moveErr.Error(),
-						"already belongs to workspace",
-					) {
-						if printErr := formatter.Print(cli.OutputEvent{
-							Command:         "move",
-							Action:          "to-scratchpad",
-							WindowID:        window.WindowID,
-							AppName:         window.AppName,
-							Workspace:       window.Workspace,
-							TargetWorkspace: constants.DefaultScratchpadWorkspaceName,
-							Result:          "skipped",
-							Message:         "already in scratchpad",
-						}); printErr != nil {
-							logger.LogError("MOVE: unable to write output", "error", printErr)
-						}
-						continue
-					}
-
-					logger.LogError(
-						"MOVE: error moving window to scratchpad",
-						"window", window,
-						"error", moveErr,
-					)
-					stderr.Println("Error: %v", moveErr)
-					// Continue with remaining windows instead of returning
-					continue
-				}
-
-				if printErr := formatter.Print(cli.OutputEvent{
-					Command:         "move",
-					Action:          "to-scratchpad",
-					WindowID:        window.WindowID,
-					AppName:         window.AppName,
-					Workspace:       window.Workspace,
-					TargetWorkspace: constants.DefaultScratchpadWorkspaceName,
-					Result:          "ok",
-				}); printErr != nil {
-					logger.LogError("MOVE: unable to write output", "error", printErr)
-				}
-			}
+			markMoving(cmd)
+			events, runErr := sp.Move(cmd.Context(), opts)
+			return writeResult(cmd, formatter, events, runErr)
 		},
 	}
 
@@ -239,49 +71,3 @@ To move all floating windows (scratchpad windows) to the scratchpad, use the --a
 
 	return command
 }
-
-// getWindowPattern determines the window pattern and focused window ID from args.
-// Returns pattern, focusedWindowID, and error.
-func getWindowPattern(
-	args []string,
-	aerospaceClient *aerospace.AeroSpaceClient,
-	log logger.Logger,
-) (string, int, error) {
-	var windowNamePattern string
-	focusedWindowID := -1
-
-	if len(args) == 0 {
-		windowNamePattern = ""
-	} else {
-		windowNamePattern = strings.TrimSpace(args[0])
-	}
-
-	if windowNamePattern == "" {
-		focusedWindow, err := aerospaceClient.GetFocusedWindow()
-		log.LogDebug(
-			"MOVE: retrieving focused window",
-			"focusedWindow", focusedWindow,
-			"error", err,
-		)
-		if err != nil {
-			stderr.Println(
-				"Error: unable to get focused window: %v",
-				err,
-			)
-			return "", -1, err
-		}
-		if focusedWindow == nil {
-			stderr.Println("Error: no focused window found")
-			return "", -1, errors.New("no focused window found")
-		}
-		focusedWindowID = focusedWindow.WindowID
-		windowNamePattern = fmt.Sprintf("^%s$", focusedWindow.AppName)
-		log.LogDebug(
-			"MOVE: using focused window app name as pattern",
-			"windowNamePattern", windowNamePattern,
-			"focusedWindowId", focusedWindowID,
-		)
-	}
-
-	return windowNamePattern, focusedWindowID, nil
-}
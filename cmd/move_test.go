@@ -3,8 +3,11 @@ package cmd_test
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"go.uber.org/mock/gomock"
 
@@ -15,14 +18,13 @@ import (
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/stderr"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
 )
 
 //nolint:gocognit // Integration-style test exercises multiple window scenarios for coverage
 func TestMoveCmd(t *testing.T) {
 	logger.SetDefaultLogger(&logger.EmptyLogger{})
-	stderr.SetBehavior(false)
 
 	t.Run("fails when pattern doesnt match any window", func(t *testing.T) {
 		logger.SetDefaultLogger(&testutils.TestingLogger{
@@ -310,6 +312,11 @@ func TestMoveCmd(t *testing.T) {
 				Return(allWindows, nil).
 				Times(1),
 
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(testutils.ExtractFocusedWindow(tree), nil).
+				Times(1),
+
 			aerospaceClient.GetWorkspacesMock().EXPECT().
 				MoveWindowToWorkspaceWithOpts(
 					workspaces.MoveWindowToWorkspaceArgs{
@@ -383,6 +390,11 @@ func TestMoveCmd(t *testing.T) {
 				Return(allWindows, nil).
 				Times(1),
 
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(focusedWindow, nil).
+				Times(1),
+
 			aerospaceClient.GetWorkspacesMock().EXPECT().
 				MoveWindowToWorkspaceWithOpts(
 					workspaces.MoveWindowToWorkspaceArgs{
@@ -460,6 +472,11 @@ func TestMoveCmd(t *testing.T) {
 				Return(allWindows, nil).
 				Times(1),
 
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(testutils.ExtractFocusedWindow(tree), nil).
+				Times(1),
+
 			aerospaceClient.GetWorkspacesMock().EXPECT().
 				MoveWindowToWorkspaceWithOpts(
 					workspaces.MoveWindowToWorkspaceArgs{
@@ -628,6 +645,11 @@ func TestMoveCmd(t *testing.T) {
 				Return(allWindows, nil).
 				Times(1),
 
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&floatingWindow1, nil).
+				Times(1),
+
 			// FocusNextTilingWindow calls - may try dfs-next first, then dfs-prev if that fails
 			aerospaceClient.GetFocusMock().EXPECT().
 				SetFocusByDFS("dfs-next", gomock.Any()).
@@ -818,6 +840,11 @@ func TestMoveCmd(t *testing.T) {
 				GetAllWindows().
 				Return(allWindows, nil).
 				Times(1),
+
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&floatingWindow, nil).
+				Times(1),
 		)
 
 		wrappedClient := aerospace.NewAeroSpaceClient(aerospaceClient)
@@ -831,4 +858,349 @@ func TestMoveCmd(t *testing.T) {
 		cmdAsString := "aerospace-scratchpad " + strings.Join(args, " ")
 		testutils.MatchSnapshot(t, allWindows, cmdAsString, out, err)
 	})
+
+	t.Run("skips floating windows already in the scratchpad", func(t *testing.T) {
+		command := "move"
+		args := []string{command, "--all-floating"}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stashedWindow := windows.Window{
+			AppName:      "Terminal",
+			WindowID:     1111,
+			WindowLayout: "floating",
+			Workspace:    constants.DefaultScratchpadWorkspaceName,
+		}
+
+		allWindows := []windows.Window{stashedWindow}
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return(allWindows, nil).
+				Times(1),
+
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&stashedWindow, nil).
+				Times(1),
+
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+				Times(0), // Already in scratchpad, no IPC call
+		)
+
+		cmd := cmd.RootCmd(aerospaceClient)
+		out, err := testutils.CmdExecute(cmd, args...)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		cmdAsString := "aerospace-scratchpad " + strings.Join(args, " ")
+		testutils.MatchSnapshot(t, allWindows, cmdAsString, out, err)
+	})
+}
+
+func TestMoveRestoresFocus(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	notepad := windows.Window{AppName: "Notepad", WindowID: 1234, Workspace: "ws1"}
+	finder := windows.Window{AppName: "Finder", WindowID: 5678, Workspace: "ws1"}
+
+	expectMove := func(aerospaceClient *testutils.MockAeroSpaceWM) []any {
+		return []any{
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{
+						WorkspaceName: constants.DefaultScratchpadWorkspaceName,
+					},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetLayoutMock().EXPECT().
+				SetLayout([]string{"floating"}, layout.SetLayoutOpts{WindowID: &notepad.WindowID}).
+				Return(nil),
+		}
+	}
+
+	t.Run("focuses the window focused before the window was shown", func(t *testing.T) {
+		isolateMarker(t)
+		if err := state.New().Update(func(st *state.State) {
+			st.SetPreviousFocus(notepad.WindowID, finder.WindowID)
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(append([]any{
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad, finder}, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&notepad, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace("ws1").
+				Return([]windows.Window{notepad, finder}, nil),
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(finder.WindowID).
+				Return(nil),
+		}, expectMove(aerospaceClient)...)...)
+
+		if _, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "move", "Notepad"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		current, err := state.New().Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(current.PreviousFocus) != 0 {
+			t.Fatalf("expected the hidden window to be forgotten, got %v", current.PreviousFocus)
+		}
+	})
+
+	t.Run("falls back to the next tiling window when it is gone", func(t *testing.T) {
+		isolateMarker(t)
+		if err := state.New().Update(func(st *state.State) {
+			st.SetPreviousFocus(notepad.WindowID, 42)
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(append([]any{
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad, finder}, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&notepad, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace("ws1").
+				Return([]windows.Window{notepad, finder}, nil),
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByDFS("dfs-next", gomock.Any()).
+				Return(nil).
+				AnyTimes(),
+		}, expectMove(aerospaceClient)...)...)
+		aerospaceClient.GetFocusMock().EXPECT().
+			SetFocusByWindowID(gomock.Any()).
+			Times(0)
+
+		if _, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "move", "Notepad"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+}
+
+func TestMoveQuota(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	notepad := windows.Window{AppName: "Notepad", WindowID: 1234, Workspace: "ws1"}
+	finder := windows.Window{AppName: "Finder", WindowID: 5678, Workspace: "ws1"}
+	stashedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	// Stashed from ws2 before the others, the first one evicted
+	oldest := windows.Window{
+		AppName:   "Terminal",
+		WindowID:  42,
+		Workspace: constants.DefaultScratchpadWorkspaceName,
+	}
+	newer := windows.Window{
+		AppName:   "Music",
+		WindowID:  43,
+		Workspace: constants.DefaultScratchpadWorkspaceName,
+	}
+
+	setup := func(t *testing.T, quota string) *testutils.MockAeroSpaceWM {
+		t.Helper()
+		isolateMarker(t)
+
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(`{"quota": `+quota+`}`), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		t.Setenv(constants.EnvAeroSpaceScratchpadConfig, path)
+
+		if err := state.New().Update(func(st *state.State) {
+			st.RecordStashed(oldest.WindowID, "ws2", stashedAt)
+			st.RecordStashed(newer.WindowID, "ws1", stashedAt.Add(time.Hour))
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad, finder, oldest, newer}, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&finder, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{
+						WorkspaceName: constants.DefaultScratchpadWorkspaceName,
+					},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetLayoutMock().EXPECT().
+				SetLayout([]string{"floating"}, layout.SetLayoutOpts{WindowID: &notepad.WindowID}).
+				Return(nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace(constants.DefaultScratchpadWorkspaceName).
+				Return([]windows.Window{oldest, newer, notepad}, nil),
+		)
+		return aerospaceClient
+	}
+
+	t.Run("returns the oldest stashed window to its workspace", func(t *testing.T) {
+		aerospaceClient := setup(t, `{"max_windows": 2}`)
+		aerospaceClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(
+				workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws2"},
+				workspaces.MoveWindowToWorkspaceOpts{WindowID: &oldest.WindowID},
+			).
+			Return(nil)
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "move", "Notepad", "--output", "json")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, `"action":"evicted","window_id":42`) ||
+			!strings.Contains(out, `"target_workspace":"ws2","result":"ok","message":"return"`) {
+			t.Fatalf("expected the oldest window evicted to ws2, got %s", out)
+		}
+
+		current, err := state.New().Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := current.Stash[oldest.WindowID]; ok {
+			t.Errorf("expected the evicted window to be forgotten, got %v", current.Stash)
+		}
+		if current.Stash[notepad.WindowID].Origin != "ws1" {
+			t.Errorf("expected the moved window stashed from ws1, got %v", current.Stash)
+		}
+	})
+
+	t.Run("tiles the evicted windows back", func(t *testing.T) {
+		aerospaceClient := setup(t, `{"max_windows": 1, "action": "tile"}`)
+		gomock.InOrder(
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws2"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &oldest.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetLayoutMock().EXPECT().
+				SetLayout([]string{"tiling"}, layout.SetLayoutOpts{WindowID: &oldest.WindowID}).
+				Return(nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws1"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &newer.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetLayoutMock().EXPECT().
+				SetLayout([]string{"tiling"}, layout.SetLayoutOpts{WindowID: &newer.WindowID}).
+				Return(nil),
+		)
+
+		if _, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "move", "Notepad"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("returns a window without origin to the focused workspace", func(t *testing.T) {
+		isolateMarker(t)
+
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(`{"quota": {"max_windows": 2}}`), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		t.Setenv(constants.EnvAeroSpaceScratchpadConfig, path)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// Moved out of the shared scratchpad, the workspace it came from is no
+		// place to evict to
+		pooled := windows.Window{
+			AppName:   "Notepad",
+			WindowID:  1234,
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+		}
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{pooled, finder, oldest, newer}, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&finder, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: ".scratchpad-pool-media"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &pooled.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetLayoutMock().EXPECT().
+				SetLayout([]string{"floating"}, layout.SetLayoutOpts{WindowID: &pooled.WindowID}).
+				Return(nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace(".scratchpad-pool-media").
+				Return([]windows.Window{oldest, newer}, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(&workspaces.Workspace{Workspace: "ws3"}, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws3"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &oldest.WindowID},
+				).
+				Return(nil),
+		)
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient),
+			"move", "Notepad", "--pool", "media", "--output", "json",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, `"target_workspace":"ws3","result":"ok","message":"return"`) {
+			t.Fatalf("expected the oldest window evicted to ws3, got %s", out)
+		}
+	})
+
+	t.Run("reports a failed eviction", func(t *testing.T) {
+		aerospaceClient := setup(t, `{"max_windows": 2}`)
+		aerospaceClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(
+				workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws2"},
+				workspaces.MoveWindowToWorkspaceOpts{WindowID: &oldest.WindowID},
+			).
+			Return(errors.New("workspace is gone"))
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "move", "Notepad", "--output", "json")
+		if err == nil {
+			t.Fatalf("expected an error, got %s", out)
+		}
+		if !strings.Contains(out, `"action":"evicted","window_id":42`) ||
+			!strings.Contains(out, `"result":"error","message":"workspace is gone"`) {
+			t.Fatalf("expected the failed eviction reported, got %s", out)
+		}
+	})
 }
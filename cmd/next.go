@@ -4,17 +4,15 @@ Copyright © 2025 Cristian Oliveira license@cristianoliveira.dev
 package cmd
 
 import (
-	"os"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/stderr"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
 )
 
 // NextCmd represents the next command.
-func NextCmd(aerospaceClient *aerospace.AeroSpaceClient) *cobra.Command {
+func NextCmd(sp *scratchpad.Scratchpad) *cobra.Command {
 	nextCmd := &cobra.Command{
 		Use:   "next",
 		Short: "Shows the next scratchpad window",
@@ -23,57 +21,28 @@ func NextCmd(aerospaceClient *aerospace.AeroSpaceClient) *cobra.Command {
 This command cycles through the scratchpad windows, displaying them in the current workspace.
 It does not send the windows back to the scratchpad, but rather focuses the next available scratchpad window.
 		`,
-		Run: func(cmd *cobra.Command, args []string) {
-			outputFormat, err := cmd.Flags().GetString("output")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formatter, err := newOutputFormatter(cmd)
 			if err != nil {
-				stderr.Println("Error: unable to get output format")
-				return
-			}
-			formatter, err := cli.NewOutputFormatter(os.Stdout, outputFormat)
-			if err != nil {
-				stderr.Println("Error: unsupported output format")
-				return
+				return err
 			}
 
-			focusedWorkspace, err := aerospaceClient.GetFocusedWorkspace()
+			pool, err := cmd.Flags().GetString("pool")
 			if err != nil {
-				stderr.Println(
-					"Error: unable to get focused workspace\n%s",
-					err,
-				)
-				return
+				return fmt.Errorf("unable to get pool flag: %w", err)
 			}
 
-			querier := aerospace.NewAerospaceQuerier(aerospaceClient.GetUnderlyingClient())
-			mover := aerospace.NewAeroSpaceMover(aerospaceClient)
-
-			window, err := querier.GetNextScratchpadWindow()
+			target, err := targetFlags(cmd)
 			if err != nil {
-				stderr.Println("Error: %v", err)
-				return
+				return err
 			}
 
-			setFocus := true
-			if moveErr := mover.MoveWindowToWorkspace(
-				window,
-				focusedWorkspace,
-				setFocus,
-			); moveErr != nil {
-				stderr.Println("Error: %v", moveErr)
-				return
-			}
-
-			if printErr := formatter.Print(cli.OutputEvent{
-				Command:         "next",
-				Action:          "to-workspace",
-				WindowID:        window.WindowID,
-				AppName:         window.AppName,
-				Workspace:       window.Workspace,
-				TargetWorkspace: focusedWorkspace.Workspace,
-				Result:          "ok",
-			}); printErr != nil {
-				stderr.Println("Error: %v", printErr)
-			}
+			markMoving(cmd)
+			events, runErr := sp.Next(cmd.Context(), scratchpad.NextOptions{
+				Pool:   pool,
+				Target: target,
+			})
+			return writeResult(cmd, formatter, events, runErr)
 		},
 	}
 
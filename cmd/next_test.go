@@ -13,13 +13,11 @@ import (
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/stderr"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
 )
 
 func TestNextCmd(t *testing.T) {
 	logger.SetDefaultLogger(&logger.EmptyLogger{})
-	stderr.SetBehavior(false)
 
 	t.Run("summon next window from scratchpad", func(t *testing.T) {
 		command := "next"
@@ -241,4 +239,152 @@ func TestNextCmd(t *testing.T) {
 			testutils.MatchSnapshot(t, nil, cmdAsString, out, err)
 		},
 	)
+
+	t.Run(
+		"reports a timeout event when AeroSpace does not answer",
+		func(t *testing.T) {
+			args := []string{"next", "--timeout", "10ms", "--output", "json"}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			release := make(chan struct{})
+			defer close(release)
+
+			aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				DoAndReturn(func() (*workspaces.Workspace, error) {
+					<-release
+					return nil, errors.New("too late")
+				}).
+				Times(1)
+
+			cmd := cmd.RootCmd(aerospaceClient)
+			out, err := testutils.CmdExecute(cmd, args...)
+			if !errors.Is(err, aerospace.ErrTimeout) {
+				t.Errorf("Expected timeout error, got %v", err)
+			}
+
+			cmdAsString := "aerospace-scratchpad " + strings.Join(args, " ")
+			testutils.MatchSnapshot(t, nil, cmdAsString, out, err)
+		},
+	)
+}
+
+func TestNextTarget(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	scratchpadWindow := windows.Window{
+		AppName:   "Slack",
+		WindowID:  9999,
+		Workspace: constants.DefaultScratchpadWorkspaceName,
+	}
+
+	expectNext := func(aerospaceClient *testutils.MockAeroSpaceWM, workspace string) {
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace(constants.DefaultScratchpadWorkspaceName).
+				Return([]windows.Window{scratchpadWindow}, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: workspace},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &scratchpadWindow.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(scratchpadWindow.WindowID).
+				Return(nil),
+		)
+	}
+
+	t.Run("brings the window to the given workspace", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		aerospaceClient.StubCommand(
+			"list-workspaces --monitor all --visible --json",
+			`[{"workspace":"ws1"},{"workspace":"chat"}]`,
+		)
+		expectNext(aerospaceClient, "chat")
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "next", "--to-workspace", "chat")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, "target_workspace=chat") {
+			t.Fatalf("expected the window brought to chat, got %s", out)
+		}
+	})
+
+	t.Run("does not focus the window on a workspace that is not visible", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		aerospaceClient.StubCommand(
+			"list-workspaces --monitor all --visible --json",
+			`[{"workspace":"ws1"}]`,
+		)
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace(constants.DefaultScratchpadWorkspaceName).
+				Return([]windows.Window{scratchpadWindow}, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "chat"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &scratchpadWindow.WindowID},
+				).
+				Return(nil),
+		)
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "next", "--to-workspace", "chat")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, "target_workspace=chat") {
+			t.Fatalf("expected the window brought to chat, got %s", out)
+		}
+	})
+
+	t.Run("brings the window to the workspace visible on the monitor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		aerospaceClient.StubCommand(
+			"list-monitors --json",
+			`[{"monitor-id":1,"monitor-name":"Built-in"},{"monitor-id":2,"monitor-name":"DELL"}]`,
+		)
+		aerospaceClient.StubCommand(
+			"list-workspaces --monitor 2 --visible --json",
+			`[{"workspace":"ws3"}]`,
+		)
+		expectNext(aerospaceClient, "ws3")
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "next", "--to-monitor", "2")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, "target_workspace=ws3") || !strings.Contains(out, "monitor=DELL") {
+			t.Fatalf("expected the window brought to ws3 on DELL, got %s", out)
+		}
+	})
+
+	t.Run("rejects invalid targets", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"next", "--to-monitor", "left"},
+			{"next", "--to-workspace", constants.DefaultScratchpadWorkspaceName},
+			{"next", "--to-workspace", "chat", "--to-monitor", "mouse"},
+		} {
+			ctrl := gomock.NewController(t)
+			aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+			if _, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), args...); err == nil {
+				t.Errorf("expected %v to fail", args)
+			}
+			ctrl.Finish()
+		}
+	})
 }
@@ -4,11 +4,23 @@ Copyright © 2025 Cristian Oliveira license@cristianoliveira.dev
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/config"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/eventlog"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/marker"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/scripts"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
 )
 
 // RootCmd represents the base command when called without any subcommands.
@@ -27,45 +39,147 @@ See:
 https://i3wm.org/docs/userguide.html#_scratchpad
 `,
 		Version: VERSION,
+		// Errors are returned by the commands, usage is only helpful for
+		// invalid arguments which cobra reports on its own.
+		SilenceUsage: true,
 	}
 
 	// Global Flags
 	rootCmd.PersistentFlags().
 		BoolP("dry-run", "n", false, "Run the command without moving windows (dry run mode)")
+	rootCmd.PersistentFlags().
+		Bool("per-workspace", false, "Stash windows in a scratchpad owned by their workspace (default from config)")
+	rootCmd.PersistentFlags().
+		Duration("timeout", config.DefaultTimeout, "Give up waiting for AeroSpace after this long, 0 disables it (default from config)")
+
+	// Scratchpad operations shared by the commands
+	sp := scratchpad.New(aerospaceClient)
+	// Loaded before running any command, shared with the hooks
+	cfg := config.Default()
+	var cancelTimeout context.CancelFunc
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		loaded, err := config.Load()
+		if err != nil {
+			return err
+		}
+		cfg = loaded
+		// Read back by writeResult for the script hooks
+		cmd.SetContext(config.NewContext(cmd.Context(), cfg))
+
+		if err = connectAeroSpace(cmd, aerospaceClient); err != nil {
+			return err
+		}
 
-	// Create custom client wrapper - now works with interface
-	customClient := aerospace.NewAeroSpaceClient(aerospaceClient)
-	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
 		dry, _ := cmd.Flags().GetBool("dry-run")
-		customClient.SetOptions(aerospace.ClientOpts{
-			DryRun: dry,
+		// Only defined by the commands moving several windows
+		atomic, _ := cmd.Flags().GetBool("atomic")
+		perWorkspace := cfg.PerWorkspace
+		if cmd.Flags().Changed("per-workspace") {
+			perWorkspace, _ = cmd.Flags().GetBool("per-workspace")
+		}
+		sp.SetOptions(scratchpad.Options{
+			DryRun:       dry,
+			Atomic:       atomic,
+			PerWorkspace: perWorkspace,
+			Sticky:       cfg.Sticky,
+			Quota:        cfg.Quota.EvictionPolicy(),
 		})
+
+		timeout := commandTimeout(cmd, &cfg)
+		// Long running commands apply it to each call on their own
+		if timeout > 0 && cmd.Annotations[annotationLongRunning] == "" {
+			// Hotkeys must never hang, every call to AeroSpace honors this deadline
+			var ctx context.Context
+			ctx, cancelTimeout = context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(ctx)
+		}
+
+		return nil
+	}
+	rootCmd.PersistentPostRun = func(_ *cobra.Command, _ []string) {
+		if cancelTimeout != nil {
+			cancelTimeout()
+		}
 	}
 
 	// Commands
 	rootCmd.AddCommand(compose([]flagsFn{
 		enableOutputFlag,
 		enableFilterFlag,
-	}, MoveCmd(customClient)))
+		enableAtomicFlag,
+		enablePoolFlag,
+	}, MoveCmd(sp)))
 	rootCmd.AddCommand(compose([]flagsFn{
 		enableOutputFlag,
 		enableFilterFlag,
-	}, ShowCmd(customClient)))
+		enableAtomicFlag,
+		enablePoolFlag,
+		enableTargetFlags,
+	}, ShowCmd(sp)))
 	rootCmd.AddCommand(compose([]flagsFn{
 		enableOutputFlag,
 		enableFilterFlag,
-	}, SummonCmd(customClient)))
-	rootCmd.AddCommand(enableOutputFlag(NextCmd(customClient)))
+		enableAtomicFlag,
+		enablePoolFlag,
+		enableTargetFlags,
+	}, SummonCmd(sp)))
+	rootCmd.AddCommand(compose([]flagsFn{
+		enableOutputFlag,
+		enablePoolFlag,
+		enableTargetFlags,
+	}, NextCmd(sp)))
 	rootCmd.AddCommand(compose([]flagsFn{
 		enableOutputFlag,
 		enableFilterFlag,
-	}, ListCmd(customClient)))
+	}, ListCmd(sp)))
 	rootCmd.AddCommand(InfoCmd(aerospaceClient))
-	rootCmd.AddCommand(HookCmd(aerospaceClient))
+	rootCmd.AddCommand(WatchCmd(aerospaceClient, &cfg))
+	rootCmd.AddCommand(HookCmd(aerospaceClient, &cfg))
 
 	return rootCmd
 }
 
+// connectAeroSpace connects the client before running the commands talking
+// to AeroSpace, so help, completion and info work while it is not running.
+func connectAeroSpace(
+	cmd *cobra.Command,
+	aerospaceClient aerospace.AeroSpaceWMClient,
+) error {
+	client, ok := aerospaceClient.(*aerospace.AeroSpaceClient)
+	if !ok || !needsAeroSpace(cmd) {
+		return nil
+	}
+
+	// AeroSpace may be restarting, e.g. after reload-config, give it a moment
+	err := client.Connect(cmd.Context(), aerospace.DefaultRetryPolicy)
+	if err != nil {
+		logger.GetDefaultLogger().LogError("Unable to connect to AeroSpace", "error", err)
+		return fmt.Errorf(
+			"%w, make sure it is running or that %s points to its socket",
+			aerospace.ErrNotRunning,
+			constants.EnvAeroSpaceSock,
+		)
+	}
+
+	return nil
+}
+
+// needsAeroSpace reports whether cmd talks to AeroSpace, which the cobra
+// builtins and the commands annotated offline do not.
+func needsAeroSpace(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[annotationOffline] != "" {
+			return false
+		}
+		switch c.Name() {
+		case "help", "completion",
+			cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return false
+		}
+	}
+	return true
+}
+
 type flagsFn func(*cobra.Command) *cobra.Command
 
 // Receive flags and attach them to the command.
@@ -85,6 +199,55 @@ Requires a key=value format. Can be used multiple times. `,
 	return command
 }
 
+func enableAtomicFlag(command *cobra.Command) *cobra.Command {
+	command.Flags().Bool(
+		"atomic", false,
+		`Move all matching windows or none: when a move fails, send the
+windows already moved back to their previous workspace and layout.`,
+	)
+	return command
+}
+
+func enablePoolFlag(command *cobra.Command) *cobra.Command {
+	command.Flags().String(
+		"pool", "",
+		`Use a named scratchpad pool (e.g. media) instead of the shared scratchpad.
+Each pool is stashed in its own hidden workspace: .scratchpad-pool-<pool>`,
+	)
+	return command
+}
+
+func enableTargetFlags(command *cobra.Command) *cobra.Command {
+	command.Flags().String(
+		"to-workspace", "",
+		"Bring the windows to this workspace instead of the focused one",
+	)
+	command.Flags().String(
+		"to-monitor", "",
+		`Bring the windows to the workspace visible on a monitor instead of the
+focused workspace: focused, mouse or a monitor id (see aerospace list-monitors)`,
+	)
+	command.MarkFlagsMutuallyExclusive("to-workspace", "to-monitor")
+	return command
+}
+
+// targetFlags returns the target selected via the --to-workspace and
+// --to-monitor flags.
+func targetFlags(cmd *cobra.Command) (scratchpad.Target, error) {
+	workspace, err := cmd.Flags().GetString("to-workspace")
+	if err != nil {
+		return scratchpad.Target{}, fmt.Errorf("unable to get to-workspace flag: %w", err)
+	}
+
+	monitor, err := cmd.Flags().GetString("to-monitor")
+	if err != nil {
+		return scratchpad.Target{}, fmt.Errorf("unable to get to-monitor flag: %w", err)
+	}
+
+	target := scratchpad.Target{Workspace: workspace, Monitor: monitor}
+	return target, target.Validate()
+}
+
 func enableOutputFlag(command *cobra.Command) *cobra.Command {
 	command.Flags().StringP(
 		"output", "o", "text", "Output format: text|json|tsv|csv",
@@ -92,16 +255,111 @@ func enableOutputFlag(command *cobra.Command) *cobra.Command {
 	return command
 }
 
+// markMoving creates the moving marker so the pull-window hook ignores the
+// workspace changes caused by this command. Failing to create it only makes
+// the hook less accurate, it never fails the command.
+func markMoving(cmd *cobra.Command) {
+	if dry, _ := cmd.Flags().GetBool("dry-run"); dry {
+		return
+	}
+
+	if err := marker.New().Create(); err != nil {
+		logger.GetDefaultLogger().LogError(
+			"MARKER: unable to create moving marker",
+			"error", err,
+		)
+	}
+}
+
+// commandTimeout returns the timeout of the global flag or the config.
+func commandTimeout(cmd *cobra.Command, cfg *config.Config) time.Duration {
+	if cmd.Flags().Changed("timeout") {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		return timeout
+	}
+	return cfg.Timeout.Duration()
+}
+
+// newOutputFormatter creates the formatter selected via the --output flag.
+func newOutputFormatter(cmd *cobra.Command) (*cli.OutputFormatter, error) {
+	outputFormat, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get output format: %w", err)
+	}
+
+	formatter, err := cli.NewOutputFormatter(os.Stdout, outputFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatter, nil
+}
+
+// writeResult prints the events produced by a command and returns the
+// command error, so events emitted before a failure are never lost. The
+// applied events are published for watch and, once printed, passed to the
+// script hooks.
+func writeResult(
+	cmd *cobra.Command,
+	formatter *cli.OutputFormatter,
+	events []cli.OutputEvent,
+	runErr error,
+) error {
+	logger := logger.GetDefaultLogger()
+
+	// Watch and the scripts read the same schema as the output
+	for i := range events {
+		events[i].SchemaVersion = scratchpad.EventSchemaVersion
+	}
+	publishEvents(events)
+
+	var printErr error
+	for _, event := range events {
+		if err := formatter.Print(event); err != nil {
+			logger.LogError("OUTPUT: unable to write output", "error", err)
+			if printErr == nil {
+				printErr = err
+			}
+		}
+	}
+
+	// Started detached once the output is written, the command never waits for them
+	scripts.New(config.FromContext(cmd.Context()).Scripts).Run(cmd.Context(), events)
+
+	if runErr != nil {
+		return runErr
+	}
+
+	return printErr
+}
+
 func Execute(
 	aerospaceClient aerospace.AeroSpaceWMClient,
 ) {
 	rootCmd := RootCmd(aerospaceClient)
 
-	if err := rootCmd.Execute(); err != nil {
+	// Cobra skips PersistentPostRun when the command fails, cancelling the
+	// parent context releases the command timeout on every path
+	ctx, cancel := context.WithCancel(context.Background())
+	err := rootCmd.ExecuteContext(ctx)
+	cancel()
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// publishEvents appends the applied events to the event log streamed by
+// watch. Failing to do so never fails the command.
+func publishEvents(events []cli.OutputEvent) {
+	applied := slices.DeleteFunc(slices.Clone(events), func(event cli.OutputEvent) bool {
+		return event.Result != "ok" || event.Command == "list"
+	})
+
+	if err := eventlog.Append(eventlog.DefaultPath(), applied); err != nil {
+		logger.GetDefaultLogger().LogError("OUTPUT: unable to publish events", "error", err)
+	}
+}
+
 // VERSION The CLI current version
 // THIS IS GENERATED DON'T EDIT
 // NOTE: to update VERSION change it to an EMPTY STRING
@@ -0,0 +1,55 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/cmd"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+)
+
+func TestRootCmdConnection(t *testing.T) {
+	// notRunning is a client whose AeroSpace never accepts the connection
+	notRunning := func() *aerospace.AeroSpaceClient {
+		client := aerospace.NewAeroSpaceClient(nil)
+		client.SetDialer(func() (aerospace.AeroSpaceWMClient, error) {
+			return nil, errors.New("connection refused")
+		})
+		return client
+	}
+
+	for _, args := range [][]string{
+		{"--help"},
+		{"--version"},
+		{"help", "move"},
+		{"completion", "zsh"},
+	} {
+		t.Run("runs "+args[0]+" without AeroSpace", func(t *testing.T) {
+			command := cmd.RootCmd(notRunning())
+			command.SetArgs(args)
+			output := &bytes.Buffer{}
+			command.SetOut(output)
+			command.SetErr(output)
+
+			if err := command.Execute(); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if output.Len() == 0 {
+				t.Fatalf("Expected some output")
+			}
+		})
+	}
+
+	t.Run("returns ErrNotRunning for the commands using AeroSpace", func(t *testing.T) {
+		command := cmd.RootCmd(notRunning())
+		command.SetArgs([]string{"list"})
+		output := &bytes.Buffer{}
+		command.SetOut(output)
+		command.SetErr(output)
+
+		if err := command.Execute(); !errors.Is(err, aerospace.ErrNotRunning) {
+			t.Fatalf("Expected ErrNotRunning, got %v", err)
+		}
+	})
+}
@@ -4,24 +4,17 @@ Copyright © 2025 Cristian Oliveira license@cristianoliveira.dev
 package cmd
 
 import (
-	"os"
-	"strings"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
-	windowsipc "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/stderr"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/config"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
 )
 
 // ShowCmd represents the show command.
-//
-//nolint:funlen,gocognit
 func ShowCmd(
-	aerospaceClient *aerospace.AeroSpaceClient,
+	sp *scratchpad.Scratchpad,
 ) *cobra.Command {
 	command := &cobra.Command{
 		Use:   "show <pattern>",
@@ -30,259 +23,92 @@ func ShowCmd(
 By default, it will set the window to floating and focus on it.
 
 Similar to I3/Sway WM, it will toggle show/hide the window if called multiple times.
+
+The toggle semantics can be changed with the policy flags, or the "show" key of
+the config file:
+  --bring        all (default) brings every match, one only the first
+  --hide-policy  all-matching (default) hides every match, focused-only the focused one
+  --focus        last (default) focuses the matches in order, first only the first, none
+  --after-hide   previous (default) focuses the window focused before the matches were
+                 shown, dfs-next the next tiling window, none
+
+On multiple monitors, "to_monitor": "mouse" in the "show" key of the config file
+brings the windows to the workspace visible on the monitor under the mouse
+cursor, unless --to-workspace or --to-monitor is given.
 `,
 		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			logger := logger.GetDefaultLogger()
-			logger.LogDebug("SHOW: start command", "args", args)
-			windowNamePattern := args[0]
-			windowNamePattern = strings.TrimSpace(windowNamePattern)
-			if windowNamePattern == "" {
-				stderr.Println("Error: <pattern> cannot be empty")
-				return
-			}
-
-			outputFormat, err := cmd.Flags().GetString("output")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formatter, err := newOutputFormatter(cmd)
 			if err != nil {
-				logger.LogError("SHOW: unable to get output flag", "error", err)
-				stderr.Println("Error: unable to get output format")
-				return
-			}
-			formatter, err := cli.NewOutputFormatter(os.Stdout, outputFormat)
-			if err != nil {
-				logger.LogError("SHOW: invalid output format", "error", err)
-				stderr.Println("Error: unsupported output format")
-				return
+				return err
 			}
 
-			// Parse filter flags
 			filterFlags, err := cmd.Flags().GetStringArray("filter")
 			if err != nil {
-				logger.LogError(
-					"SHOW: unable to get filter flags",
-					"error",
-					err,
-				)
-				stderr.Println("Error: unable to get filter flags")
-				return
+				return fmt.Errorf("unable to get filter flags: %w", err)
 			}
 
-			focusedWorkspace, err := aerospaceClient.GetFocusedWorkspace()
+			pool, err := cmd.Flags().GetString("pool")
 			if err != nil {
-				logger.LogError(
-					"SHOW: unable to get focused workspace",
-					"error",
-					err,
-				)
-				stderr.Println("Error: unable to get focused workspace")
-				return
+				return fmt.Errorf("unable to get pool flag: %w", err)
 			}
-			logger.LogDebug(
-				"SHOW: retrieved focused workspace",
-				"workspace",
-				focusedWorkspace,
-			)
-
-			querier := aerospace.NewAerospaceQuerier(aerospaceClient.GetUnderlyingClient())
-			mover := aerospace.NewAeroSpaceMover(aerospaceClient)
 
-			windows, err := querier.GetFilteredWindows(
-				windowNamePattern,
-				filterFlags,
-			)
+			policy, err := togglePolicy(cmd)
 			if err != nil {
-				stderr.Printf("Error: %v\n", err)
-				return
+				return err
 			}
 
-			var windowsOutsideView []windowsipc.Window
-			var windowsInFocusedWorkspace []windowsipc.Window
-			var hasAtLeastOneWindowFocused bool
-			for _, window := range windows {
-				isWindowInFocusedWorkspace := window.Workspace == focusedWorkspace.Workspace
-				if isWindowInFocusedWorkspace {
-					windowsInFocusedWorkspace = append(
-						windowsInFocusedWorkspace,
-						window,
-					)
-
-					isWindowFocused, focusErr := querier.IsWindowFocused(
-						window.WindowID,
-					)
-					if focusErr != nil {
-						stderr.Printf(
-							"Error: unable to check if window '%+v' is focused\n",
-							window,
-						)
-						return
-					}
-
-					// Make sure that once hasAtLeastOneWindowFocused is true, it will remain true
-					hasAtLeastOneWindowFocused = hasAtLeastOneWindowFocused ||
-						isWindowFocused
-				} else {
-					windowsOutsideView = append(windowsOutsideView, window)
-				}
-
-				logger.LogDebug(
-					"SHOW: loop",
-					"windowsOutsideView", windowsOutsideView,
-					"windowsInFocusedWorkspace", windowsInFocusedWorkspace,
-					"hasAtLeastOneWindowFocused", hasAtLeastOneWindowFocused,
-				)
-			}
-
-			logger.LogDebug(
-				"SHOW: filtered windows",
-				"windowsOutsideView", windowsOutsideView,
-				"windowsInFocusedWorkspace", windowsInFocusedWorkspace,
-				"hasAtLeastOneWindowFocused", hasAtLeastOneWindowFocused,
-			)
-
-			for _, window := range windowsOutsideView {
-				moveErr := mover.MoveWindowToWorkspace(
-					&window,
-					focusedWorkspace,
-					!hasAtLeastOneWindowFocused,
-				)
-				if moveErr != nil {
-					stderr.Printf(
-						"Error: unable to move window '%+v' to scratchpad\n%s",
-						window,
-						moveErr,
-					)
-					return
-				}
-
-				if printErr := formatter.Print(cli.OutputEvent{
-					Command:         "show",
-					Action:          "to-workspace",
-					WindowID:        window.WindowID,
-					AppName:         window.AppName,
-					Workspace:       window.Workspace,
-					TargetWorkspace: focusedWorkspace.Workspace,
-					Result:          "ok",
-				}); printErr != nil {
-					logger.LogError("SHOW: unable to write output", "error", printErr)
-				}
+			target, err := targetFlags(cmd)
+			if err != nil {
+				return err
 			}
-
-			// NOTE: To avoid the ping pong of windows, so priority is
-			// for bringing windows to the focused workspace
-			if len(windowsOutsideView) > 0 {
-				// Make sure to bring the remaining matched windows to the front
-				for _, window := range windowsInFocusedWorkspace {
-					err = aerospaceClient.SetFocusByWindowID(window.WindowID)
-					if err != nil {
-						stderr.Printf(
-							"Error: unable to set focus to window '%+v'\n%s",
-							window,
-							err,
-						)
-						return
-					}
-					logger.LogDebug(
-						"SHOW: set focus to window",
-						"window",
-						window,
-					)
-					if printErr := formatter.Print(cli.OutputEvent{
-						Command:   "show",
-						Action:    "focus",
-						WindowID:  window.WindowID,
-						AppName:   window.AppName,
-						Workspace: window.Workspace,
-						Result:    "ok",
-					}); printErr != nil {
-						logger.LogError("SHOW: unable to write output", "error", printErr)
-					}
-				}
-
-				return
+			if target.IsFocused() {
+				target.Monitor = config.FromContext(cmd.Context()).Show.ToMonitor
 			}
 
-			for i, window := range windowsInFocusedWorkspace {
-				logger.LogDebug(
-					"SHOW: processing window in focused workspace",
-					"window", window,
-					"hasAtLeastOneWindowFocused", hasAtLeastOneWindowFocused,
-				)
-				if hasAtLeastOneWindowFocused { //nolint:nestif // conditional flow mirrors show toggle behavior
-					if i == 0 {
-						logger.LogDebug(
-							"SHOW: first window to hide, will focus next tiling window after hiding",
-							"window",
-							window,
-						)
-						if err = aerospaceClient.FocusNextTilingWindow(); err != nil {
-							// No need to exit here, just log the error and continue
-							logger.LogError(
-								"SHOW: unable to focus next tiling window",
-								"error",
-								err,
-							)
-						}
-					}
+			markMoving(cmd)
+			events, runErr := sp.Show(cmd.Context(), scratchpad.ShowOptions{
+				Selector: scratchpad.Selector{
+					Pattern: args[0],
+					Filters: filterFlags,
+					Pool:    pool,
+				},
+				Target: target,
+				Policy: policy,
+			})
+			return writeResult(cmd, formatter, events, runErr)
+		},
+	}
 
-					moveErr := mover.MoveWindowToScratchpad(window)
-					if moveErr != nil {
-						logger.LogDebug(
-							"Error: unable to move window '%+v' to scratchpad\n%s",
-							"window",
-							window,
-							"error",
-							moveErr,
-						)
-						if printErr := formatter.Print(cli.OutputEvent{
-							Command:         "show",
-							Action:          "to-scratchpad",
-							WindowID:        window.WindowID,
-							AppName:         window.AppName,
-							Workspace:       window.Workspace,
-							TargetWorkspace: constants.DefaultScratchpadWorkspaceName,
-							Result:          "error",
-							Message:         moveErr.Error(),
-						}); printErr != nil {
-							logger.LogError("SHOW: unable to write output", "error", printErr)
-						}
-						continue
-					}
+	command.Flags().String("bring", "", "Windows to bring: all, one")
+	command.Flags().String("hide-policy", "", "Windows to hide: all-matching, focused-only")
+	command.Flags().String("focus", "", "Window to focus: last, first, none")
+	command.Flags().String("after-hide", "", "Window to focus after hiding: previous, dfs-next, none")
 
-					if printErr := formatter.Print(cli.OutputEvent{
-						Command:         "show",
-						Action:          "to-scratchpad",
-						WindowID:        window.WindowID,
-						AppName:         window.AppName,
-						Workspace:       window.Workspace,
-						TargetWorkspace: constants.DefaultScratchpadWorkspaceName,
-						Result:          "ok",
-					}); printErr != nil {
-						logger.LogError("SHOW: unable to write output", "error", printErr)
-					}
-					continue
-				}
+	return command
+}
 
-				err = aerospaceClient.SetFocusByWindowID(window.WindowID)
-				if err != nil {
-					stderr.Printf(
-						"Error: unable to set focus to window '%+v'\n%s",
-						window,
-						err,
-					)
-					return
-				}
-				if printErr := formatter.Print(cli.OutputEvent{
-					Command:   "show",
-					Action:    "focus",
-					WindowID:  window.WindowID,
-					AppName:   window.AppName,
-					Workspace: window.Workspace,
-					Result:    "ok",
-				}); printErr != nil {
-					logger.LogError("SHOW: unable to write output", "error", printErr)
-				}
-			}
-		},
+// togglePolicy returns the policies of the config overridden by the flags.
+func togglePolicy(cmd *cobra.Command) (scratchpad.TogglePolicy, error) {
+	policy := config.FromContext(cmd.Context()).Show.TogglePolicy()
+	for _, flag := range []struct {
+		name  string
+		value *string
+	}{
+		{"bring", &policy.Bring},
+		{"hide-policy", &policy.Hide},
+		{"focus", &policy.Focus},
+		{"after-hide", &policy.AfterHide},
+	} {
+		if !cmd.Flags().Changed(flag.name) {
+			continue
+		}
+		value, err := cmd.Flags().GetString(flag.name)
+		if err != nil {
+			return policy, fmt.Errorf("unable to get %s flag: %w", flag.name, err)
+		}
+		*flag.value = value
 	}
-	return command
+
+	return policy, policy.Validate()
 }
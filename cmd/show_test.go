@@ -2,6 +2,8 @@ package cmd_test
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -14,7 +16,7 @@ import (
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/stderr"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
 )
 
@@ -29,8 +31,6 @@ func TestShowCmd(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
-		stderr.SetBehavior(false)
-
 		tree := []testutils.AeroSpaceTree{
 			{
 				Windows: []windows.Window{
@@ -308,6 +308,9 @@ func TestShowCmd(t *testing.T) {
 	t.Run(
 		"summon the window to the current workspace if in another workspace",
 		func(t *testing.T) {
+			// The shown windows are recorded in the session state
+			isolateMarker(t)
+
 			command := "show"
 			args := []string{command, "Finder"}
 
@@ -360,6 +363,12 @@ func TestShowCmd(t *testing.T) {
 				Return(focusedTree.Workspace, nil).
 				Times(1)
 
+			// Remember the window to focus again once hidden
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(focusedWindow, nil).
+				Times(1)
+
 			gomock.InOrder(
 				aerospaceClient.GetWorkspacesMock().EXPECT().
 					MoveWindowToWorkspaceWithOpts(
@@ -420,6 +429,9 @@ func TestShowCmd(t *testing.T) {
 
 	t.Run("MultipleWindows", func(tt *testing.T) {
 		tt.Run("brings all windows to focused workspace", func(t *testing.T) {
+			// The shown windows are recorded in the session state
+			isolateMarker(t)
+
 			command := "show"
 			args := []string{command, "Finder"}
 
@@ -476,6 +488,12 @@ func TestShowCmd(t *testing.T) {
 				Return(focusedTree.Workspace, nil).
 				Times(1)
 
+			// Remember the window to focus again once hidden
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(testutils.ExtractFocusedWindow(tree), nil).
+				Times(1)
+
 			gomock.InOrder(
 				// Send first window
 				aerospaceClient.GetWorkspacesMock().EXPECT().
@@ -539,6 +557,137 @@ func TestShowCmd(t *testing.T) {
 			)
 		})
 
+		tt.Run("rolls back the moved windows when a move fails with --atomic", func(t *testing.T) {
+			// The shown windows are recorded in the session state
+			isolateMarker(t)
+
+			command := "show"
+			args := []string{command, "Finder", "--atomic"}
+
+			ctrl := gomock.NewController(tt)
+			defer ctrl.Finish()
+
+			tree := []testutils.AeroSpaceTree{
+				{
+					Windows: []windows.Window{
+						{
+							AppName:   "Finder1",
+							WindowID:  5678,
+							Workspace: "ws1",
+						},
+						{
+							AppName:   "Finder2",
+							WindowID:  5679,
+							Workspace: "ws1",
+						},
+					},
+					Workspace: &workspaces.Workspace{
+						Workspace: "ws1",
+					},
+					FocusedWindowID: 0, // Not focused
+				},
+				{
+					Windows: []windows.Window{
+						{
+							AppName:   "Terminal",
+							WindowID:  91011,
+							Workspace: "ws2",
+						},
+					},
+					Workspace: &workspaces.Workspace{
+						Workspace: "ws2",
+					},
+					FocusedWindowID: 91011,
+				},
+			}
+
+			allWindows := testutils.ExtractAllWindows(tree)
+			focusedTree := testutils.ExtractFocusedTree(tree)
+
+			aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return(allWindows, nil).
+				Times(1)
+
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(focusedTree.Workspace, nil).
+				Times(1)
+
+			// Remember the window to focus again once hidden
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(testutils.ExtractFocusedWindow(tree), nil).
+				Times(1)
+
+			gomock.InOrder(
+				// First window is moved
+				aerospaceClient.GetWorkspacesMock().EXPECT().
+					MoveWindowToWorkspaceWithOpts(
+						workspaces.MoveWindowToWorkspaceArgs{
+							WorkspaceName: focusedTree.Workspace.Workspace,
+						},
+						workspaces.MoveWindowToWorkspaceOpts{
+							WindowID: &tree[0].Windows[0].WindowID,
+						},
+					).
+					Return(nil).
+					Times(1),
+				aerospaceClient.GetFocusMock().EXPECT().
+					SetFocusByWindowID(tree[0].Windows[0].WindowID).
+					Return(nil).
+					Times(1),
+
+				// 2nd window fails
+				aerospaceClient.GetWorkspacesMock().EXPECT().
+					MoveWindowToWorkspaceWithOpts(
+						workspaces.MoveWindowToWorkspaceArgs{
+							WorkspaceName: focusedTree.Workspace.Workspace,
+						},
+						workspaces.MoveWindowToWorkspaceOpts{
+							WindowID: &tree[0].Windows[1].WindowID,
+						},
+					).
+					Return(fmt.Errorf("window is gone")).
+					Times(1),
+
+				// First window goes back
+				aerospaceClient.GetWorkspacesMock().EXPECT().
+					MoveWindowToWorkspaceWithOpts(
+						workspaces.MoveWindowToWorkspaceArgs{
+							WorkspaceName: "ws1",
+						},
+						workspaces.MoveWindowToWorkspaceOpts{
+							WindowID: &tree[0].Windows[0].WindowID,
+						},
+					).
+					Return(nil).
+					Times(1),
+			)
+
+			cmd := cmd.RootCmd(aerospaceClient)
+			out, err := testutils.CmdExecute(cmd, args...)
+			if err == nil {
+				t.Errorf("Expected error, got %v", out)
+			}
+
+			cmdAsString := "aerospace-scratchpad " + strings.Join(
+				args,
+				" ",
+			) + "\n"
+			expectedError := fmt.Sprintf("Error\n%+v", err)
+			testutils.MatchSnapshot(
+				t,
+				tree,
+				cmdAsString,
+				"Output",
+				out,
+				expectedError,
+			)
+		})
+
 		tt.Run(
 			"sends all windows to scratchpad if at least one window is focused",
 			func(t *testing.T) {
@@ -679,6 +828,9 @@ func TestShowCmd(t *testing.T) {
 		tt.Run(
 			"gives priority to bringing scratchpads together",
 			func(t *testing.T) {
+				// The shown windows are recorded in the session state
+				isolateMarker(t)
+
 				command := "show"
 				args := []string{command, "Finder"}
 
@@ -740,10 +892,12 @@ func TestShowCmd(t *testing.T) {
 					Times(1)
 
 				gomock.InOrder(
+					// Checks the matched window, then remembers the window
+					// to focus again once hidden
 					aerospaceClient.GetWindowsMock().EXPECT().
 						GetFocusedWindow().
 						Return(focusedWindow, nil).
-						Times(1),
+						Times(2),
 
 					aerospaceClient.GetWorkspacesMock().EXPECT().
 						MoveWindowToWorkspaceWithOpts(
@@ -914,6 +1068,9 @@ func TestShowCmd(t *testing.T) {
 		tt.Run(
 			"Filter flag: brings any windows that matches filter",
 			func(ttt *testing.T) {
+				// The shown windows are recorded in the session state
+				isolateMarker(ttt)
+
 				command := "show"
 				args := []string{
 					command,
@@ -975,6 +1132,12 @@ func TestShowCmd(t *testing.T) {
 					Return(focusedTree.Workspace, nil).
 					Times(1)
 
+				// Remember the window to focus again once hidden
+				aerospaceClient.GetWindowsMock().EXPECT().
+					GetFocusedWindow().
+					Return(testutils.ExtractFocusedWindow(tree), nil).
+					Times(1)
+
 				gomock.InOrder(
 					// Send first window
 					aerospaceClient.GetWorkspacesMock().EXPECT().
@@ -1025,6 +1188,9 @@ func TestShowCmd(t *testing.T) {
 		tt.Run(
 			"Filter flag: brings any windows that matches filter - allow multiple",
 			func(ttt *testing.T) {
+				// The shown windows are recorded in the session state
+				isolateMarker(ttt)
+
 				command := "show"
 				args := []string{command,
 					"Finder",
@@ -1095,6 +1261,12 @@ func TestShowCmd(t *testing.T) {
 					Return(focusedTree.Workspace, nil).
 					Times(1)
 
+				// Remember the window to focus again once hidden
+				aerospaceClient.GetWindowsMock().EXPECT().
+					GetFocusedWindow().
+					Return(testutils.ExtractFocusedWindow(tree), nil).
+					Times(1)
+
 				gomock.InOrder(
 					// Send first window
 					aerospaceClient.GetWorkspacesMock().EXPECT().
@@ -1303,3 +1475,289 @@ func TestShowCmd(t *testing.T) {
 		)
 	})
 }
+
+func TestShowPolicies(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	notepad := windows.Window{AppName: "Notepad", Workspace: constants.DefaultScratchpadWorkspaceName, WindowID: 1234}
+	finder := windows.Window{AppName: "Finder", Workspace: "ws1", WindowID: 5678}
+	focusedWorkspace := &workspaces.Workspace{Workspace: "ws1"}
+
+	t.Run("focuses the previous window again after hiding by default", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		shown := notepad
+		shown.Workspace = "ws1"
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			// show brings Notepad and records Finder as the previous window
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(focusedWorkspace, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad, finder}, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&finder, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws1"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(notepad.WindowID).
+				Return(nil),
+
+			// show hides Notepad and focuses Finder
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(focusedWorkspace, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{shown, finder}, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&shown, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace("ws1").
+				Return([]windows.Window{shown, finder}, nil),
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(finder.WindowID).
+				Return(nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{
+						WorkspaceName: constants.DefaultScratchpadWorkspaceName,
+					},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetLayoutMock().EXPECT().
+				SetLayout([]string{"floating"}, layout.SetLayoutOpts{WindowID: &notepad.WindowID}).
+				Return(nil),
+		)
+
+		for range 2 {
+			_, err := testutils.CmdExecute(
+				cmd.RootCmd(aerospaceClient),
+				"show", "Notepad",
+			)
+			if err != nil {
+				t.Fatalf("expected success, got error %v", err)
+			}
+		}
+	})
+
+	t.Run("brings without focusing", func(t *testing.T) {
+		// The shown windows are recorded in the session state
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(focusedWorkspace, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad, finder}, nil),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&finder, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws1"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil),
+		)
+		aerospaceClient.GetFocusMock().EXPECT().
+			SetFocusByWindowID(gomock.Any()).
+			Times(0)
+
+		_, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "show", "Notepad", "--focus", "none")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+
+	t.Run("fails on an unknown policy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// No expectations, the command must not talk to AeroSpace
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		_, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "show", "Notepad", "--bring", "some")
+		if err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+	})
+}
+
+func TestShowTarget(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	t.Run("stages the window on another workspace without making it sticky", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		notepad := windows.Window{
+			AppName:   "Notepad",
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+			WindowID:  1234,
+		}
+		finder := windows.Window{AppName: "Finder", Workspace: "ws1", WindowID: 5678}
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		aerospaceClient.StubCommand(
+			"list-workspaces --monitor all --visible --json",
+			`[{"workspace":"ws1"},{"workspace":"chat"}]`,
+		)
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad, finder}, nil),
+			// Remember the window to focus again once hidden
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&finder, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "chat"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(notepad.WindowID).
+				Return(nil),
+		)
+
+		out, err := testutils.CmdExecute(
+			cmd.RootCmd(aerospaceClient),
+			"show", "Notepad", "--to-workspace", "chat",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, "target_workspace=chat") {
+			t.Fatalf("expected the window brought to chat, got %s", out)
+		}
+
+		current, err := state.New().Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(current.Sticky) != 0 {
+			t.Fatalf("expected no sticky window, got %v", current.Sticky)
+		}
+	})
+
+	t.Run("leaves the focus alone when the workspace is not visible", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		notepad := windows.Window{
+			AppName:   "Notepad",
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+			WindowID:  1234,
+		}
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		aerospaceClient.StubCommand(
+			"list-workspaces --monitor all --visible --json",
+			`[{"workspace":"ws1"}]`,
+		)
+		// Focusing the window would switch to chat
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad}, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "chat"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil),
+		)
+
+		out, err := testutils.CmdExecute(
+			cmd.RootCmd(aerospaceClient),
+			"show", "Notepad", "--to-workspace", "chat",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, "target_workspace=chat") {
+			t.Fatalf("expected the window brought to chat, got %s", out)
+		}
+	})
+
+	t.Run("brings the window to the monitor under the mouse set in the config", func(t *testing.T) {
+		isolateMarker(t)
+
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(`{"show": {"to_monitor": "mouse"}}`), 0o600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		t.Setenv(constants.EnvAeroSpaceScratchpadConfig, path)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		notepad := windows.Window{
+			AppName:   "Notepad",
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+			WindowID:  1234,
+		}
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		aerospaceClient.StubCommand(
+			"list-monitors --mouse --json",
+			`[{"monitor-id":2,"monitor-name":"DELL U2720Q"}]`,
+		)
+		aerospaceClient.StubCommand(
+			"list-workspaces --monitor 2 --visible --json",
+			`[{"workspace":"ws2"}]`,
+		)
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad}, nil),
+			// Remember the window to focus again once hidden
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&windows.Window{AppName: "Finder", Workspace: "ws1", WindowID: 5678}, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws2"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(notepad.WindowID).
+				Return(nil),
+		)
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "show", "Notepad", "--output", "json")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, `"target_workspace":"ws2"`) ||
+			!strings.Contains(out, `"monitor":"DELL U2720Q"`) {
+			t.Fatalf("expected the window brought to ws2 on DELL U2720Q, got %s", out)
+		}
+	})
+}
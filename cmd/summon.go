@@ -4,22 +4,17 @@ Copyright © 2025 Cristian Oliveira license@cristianoliveira.dev
 package cmd
 
 import (
-	"os"
-	"strings"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/stderr"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
 )
 
 // SummonCmd represents the summon command.
-//
-//nolint:funlen,gocognit,nestif // command wiring and validation keep this function long/branchy
 func SummonCmd(
-	aerospaceClient *aerospace.AeroSpaceClient,
+	sp *scratchpad.Scratchpad,
 ) *cobra.Command {
 	command := &cobra.Command{
 		Use:   "summon <pattern>",
@@ -28,6 +23,9 @@ func SummonCmd(
 
 This command brings a window from the scratchpad to the current workspace using a regex to match the window name or title.
 If no pattern is provided, it summons the first window in the scratchpad.
+
+With --toggle, the matched windows are hidden instead when they are all in the
+current workspace and one of them is focused, the same way show decides it.
 `,
 
 		Args: cobra.MatchAll(
@@ -35,142 +33,48 @@ If no pattern is provided, it summons the first window in the scratchpad.
 			cli.ValidateAllNonEmpty,
 		),
 
-		Run: func(cmd *cobra.Command, args []string) {
-			logger := logger.GetDefaultLogger()
-			windowNamePattern := strings.TrimSpace(args[0])
-
-			outputFormat, err := cmd.Flags().GetString("output")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			formatter, err := newOutputFormatter(cmd)
 			if err != nil {
-				logger.LogError("SUMMON: unable to get output flag", "error", err)
-				stderr.Println("Error: unable to get output format")
-				return
+				return err
 			}
-			formatter, err := cli.NewOutputFormatter(os.Stdout, outputFormat)
+
+			filterFlags, err := cmd.Flags().GetStringArray("filter")
 			if err != nil {
-				logger.LogError("SUMMON: invalid output format", "error", err)
-				stderr.Println("Error: unsupported output format")
-				return
+				return fmt.Errorf("unable to get filter flags: %w", err)
 			}
 
-			focusedWorkspace, err := aerospaceClient.GetFocusedWorkspace()
+			pool, err := cmd.Flags().GetString("pool")
 			if err != nil {
-				logger.LogError(
-					"SUMMON: unable to get focused workspace",
-					"error",
-					err,
-				)
-				stderr.Println("Error: unable to get focused workspace")
-				return
+				return fmt.Errorf("unable to get pool flag: %w", err)
 			}
 
-			// Parse filter flags
-			filterFlags, err := cmd.Flags().GetStringArray("filter")
+			toggle, err := cmd.Flags().GetBool("toggle")
 			if err != nil {
-				logger.LogError(
-					"SUMMON: unable to get filter flags",
-					"error",
-					err,
-				)
-				stderr.Println("Error: unable to get filter flags")
-				return
+				return fmt.Errorf("unable to get toggle flag: %w", err)
 			}
 
-			// Filter windows using the shared querier
-			querier := aerospace.NewAerospaceQuerier(aerospaceClient.GetUnderlyingClient())
-			mover := aerospace.NewAeroSpaceMover(aerospaceClient)
-
-			windows, err := querier.GetFilteredWindows(
-				windowNamePattern,
-				filterFlags,
-			)
+			target, err := targetFlags(cmd)
 			if err != nil {
-				logger.LogError(
-					"SUMMON: unable to get filtered windows",
-					"error",
-					err,
-				)
-				stderr.Println("Error: %v", err)
-				return
+				return err
 			}
 
-			for _, window := range windows {
-				setFocus := true
-				moveErr := mover.MoveWindowToWorkspace(
-					&window,
-					focusedWorkspace,
-					setFocus,
-				)
-				if moveErr != nil {
-					if strings.Contains(
-						moveErr.Error(),
-						"already belongs to workspace",
-					) {
-						logger.LogDebug(
-							"SUMMON: window already belongs to workspace",
-							"window",
-							window,
-							"workspace",
-							focusedWorkspace,
-							"error",
-							moveErr,
-						)
-						if focusErr := aerospaceClient.SetFocusByWindowID(window.WindowID); focusErr != nil {
-							logger.LogError(
-								"SUMMON: unable to set focus to window",
-								"window",
-								window,
-								"error",
-								focusErr,
-							)
-							stderr.Printf(
-								"Error: unable to set focus to window '%+v'\n%s",
-								window,
-								focusErr,
-							)
-							return
-						}
-
-						if printErr := formatter.Print(cli.OutputEvent{
-							Command:         "summon",
-							Action:          "to-workspace",
-							WindowID:        window.WindowID,
-							AppName:         window.AppName,
-							Workspace:       window.Workspace,
-							TargetWorkspace: focusedWorkspace.Workspace,
-							Result:          "skipped",
-							Message:         "already in target workspace",
-						}); printErr != nil {
-							logger.LogError("SUMMON: unable to write output", "error", printErr)
-						}
-						continue
-					}
-
-					logger.LogDebug(
-						"SUMMON: unable to move window to workspace",
-						"window",
-						window,
-						"workspace",
-						focusedWorkspace,
-						"error",
-						moveErr,
-					)
-					stderr.Println("Error: %v", moveErr)
-					return
-				}
-
-				if printErr := formatter.Print(cli.OutputEvent{
-					Command:         "summon",
-					Action:          "to-workspace",
-					WindowID:        window.WindowID,
-					AppName:         window.AppName,
-					Workspace:       window.Workspace,
-					TargetWorkspace: focusedWorkspace.Workspace,
-					Result:          "ok",
-				}); printErr != nil {
-					logger.LogError("SUMMON: unable to write output", "error", printErr)
-				}
-			}
+			markMoving(cmd)
+			events, runErr := sp.Summon(cmd.Context(), scratchpad.SummonOptions{
+				Selector: scratchpad.Selector{
+					Pattern: args[0],
+					Filters: filterFlags,
+					Pool:    pool,
+				},
+				Target: target,
+				Toggle: toggle,
+			})
+			return writeResult(cmd, formatter, events, runErr)
 		},
 	}
+
+	command.Flags().
+		Bool("toggle", false, "Hide the windows when they are already in the current workspace and focused")
+
 	return command
 }
@@ -7,19 +7,20 @@ import (
 
 	"go.uber.org/mock/gomock"
 
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/layout"
 	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
 	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
 	"github.com/cristianoliveira/aerospace-scratchpad/cmd"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/stderr"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
 )
 
 //nolint:gocognit // Integration test covers multiple window flows in one place
 func TestSummonCmd(t *testing.T) {
 	logger.SetDefaultLogger(&logger.EmptyLogger{})
-	stderr.SetBehavior(false)
 
 	t.Run("successfully summons a window by pattern", func(t *testing.T) {
 		command := "summon"
@@ -32,8 +33,9 @@ func TestSummonCmd(t *testing.T) {
 			{
 				Windows: []windows.Window{
 					{
-						AppName:  "Notepad",
-						WindowID: 1234,
+						AppName:   "Notepad",
+						Workspace: constants.DefaultScratchpadWorkspaceName,
+						WindowID:  1234,
 					},
 					{
 						AppName:  "Finder",
@@ -282,8 +284,9 @@ func TestSummonCmd(t *testing.T) {
 				{
 					Windows: []windows.Window{
 						{
-							AppName:  "Notepad",
-							WindowID: 1234,
+							AppName:   "Notepad",
+							Workspace: constants.DefaultScratchpadWorkspaceName,
+							WindowID:  1234,
 						},
 					},
 					Workspace: &workspaces.Workspace{
@@ -353,8 +356,9 @@ func TestSummonCmd(t *testing.T) {
 			{
 				Windows: []windows.Window{
 					{
-						AppName:  "Notepad",
-						WindowID: 1234,
+						AppName:   "Notepad",
+						Workspace: constants.DefaultScratchpadWorkspaceName,
+						WindowID:  1234,
 					},
 				},
 				Workspace: &workspaces.Workspace{
@@ -428,12 +432,14 @@ func TestSummonCmd(t *testing.T) {
 			{
 				Windows: []windows.Window{
 					{
-						AppName:  "Notepad",
-						WindowID: 1234,
+						AppName:   "Notepad",
+						Workspace: constants.DefaultScratchpadWorkspaceName,
+						WindowID:  1234,
 					},
 					{
-						AppName:  "TextEdit",
-						WindowID: 5678,
+						AppName:   "TextEdit",
+						Workspace: constants.DefaultScratchpadWorkspaceName,
+						WindowID:  5678,
 					},
 					{
 						AppName:  "Finder",
@@ -518,6 +524,66 @@ func TestSummonCmd(t *testing.T) {
 		testutils.MatchSnapshot(t, tree, cmdAsString, out, err)
 	})
 
+	t.Run("skips move and focuses window already in focused workspace", func(t *testing.T) {
+		command := "summon"
+		args := []string{command, "Notepad"}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tree := []testutils.AeroSpaceTree{
+			{
+				Windows: []windows.Window{
+					{
+						AppName:  "Notepad",
+						WindowID: 1234,
+					},
+					{
+						AppName:  "Finder",
+						WindowID: 5678,
+					},
+				},
+				Workspace: &workspaces.Workspace{
+					Workspace: "ws1",
+				},
+				FocusedWindowID: 5678,
+			},
+		}
+		allWindows := testutils.ExtractAllWindows(tree)
+		focusedWorkspace := &workspaces.Workspace{Workspace: "ws1"}
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(focusedWorkspace, nil).
+				Times(1),
+
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return(allWindows, nil).
+				Times(1),
+
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+				Times(0), // Already in the focused workspace, no IPC call
+
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(1234).
+				Return(nil).
+				Times(1),
+		)
+
+		cmd := cmd.RootCmd(aerospaceClient)
+		out, err := testutils.CmdExecute(cmd, args...)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		cmdAsString := "aerospace-scratchpad " + strings.Join(args, " ")
+		testutils.MatchSnapshot(t, tree, cmdAsString, out, err)
+	})
+
 	t.Run("handles empty pattern gracefully", func(t *testing.T) {
 		command := "summon"
 		args := []string{command, ""}
@@ -571,8 +637,9 @@ func TestSummonCmd(t *testing.T) {
 			{
 				Windows: []windows.Window{
 					{
-						AppName:  "Notepad",
-						WindowID: 1234,
+						AppName:   "Notepad",
+						Workspace: constants.DefaultScratchpadWorkspaceName,
+						WindowID:  1234,
 					},
 					{
 						AppName:  "Finder",
@@ -625,3 +692,309 @@ func TestSummonCmd(t *testing.T) {
 		testutils.MatchSnapshot(t, tree, cmdAsString, out, err)
 	})
 }
+
+func TestSummonToggle(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	notepad := windows.Window{AppName: "Notepad", Workspace: "ws1", WindowID: 1234}
+	finder := windows.Window{AppName: "Finder", Workspace: "ws1", WindowID: 5678}
+	focusedWorkspace := &workspaces.Workspace{Workspace: "ws1"}
+
+	t.Run("hides the focused window already in the workspace", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(focusedWorkspace, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad, finder}, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&notepad, nil).
+				Times(1),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{
+						WorkspaceName: constants.DefaultScratchpadWorkspaceName,
+					},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil).
+				Times(1),
+			aerospaceClient.GetLayoutMock().EXPECT().
+				SetLayout(
+					[]string{"floating"},
+					layout.SetLayoutOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil).
+				Times(1),
+		)
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "summon", "--toggle", "Notepad")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		expected := "command=summon action=to-scratchpad window_id=1234 app_name=Notepad workspace=ws1 " +
+			"target_workspace=.scratchpad result=ok message=\"\" pool=\"\" monitor=\"\" " +
+			"window_title=\"\" app_bundle_id=\"\" window_layout=\"\" parent_layout=\"\" duration_ms=0 schema_version=2\n"
+		if out != expected {
+			t.Fatalf("expected event %q, got %q", expected, out)
+		}
+	})
+
+	t.Run("summons the window when it is not focused", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(focusedWorkspace, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad, finder}, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&finder, nil).
+				Times(1),
+			// Recorded to be focused again when the window is hidden
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&finder, nil).
+				Times(1),
+			// Already in the workspace, summon only focuses it
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(notepad.WindowID).
+				Return(nil).
+				Times(1),
+		)
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "summon", "--toggle", "Notepad")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, "action=to-workspace window_id=1234") {
+			t.Fatalf("expected the window to be summoned, got %q", out)
+		}
+	})
+
+	t.Run("records the window focused before the summon", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stashed := notepad
+		stashed.Workspace = constants.DefaultScratchpadWorkspaceName
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(focusedWorkspace, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{stashed, finder}, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&finder, nil).
+				Times(1),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws1"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &stashed.WindowID},
+				).
+				Return(nil).
+				Times(1),
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(stashed.WindowID).
+				Return(nil).
+				Times(1),
+		)
+
+		if _, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "summon", "--toggle", "Notepad"); err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		current, err := state.New().Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if current.PreviousFocus[notepad.WindowID] != finder.WindowID {
+			t.Fatalf("expected finder to be focused again on hide, got %v", current.PreviousFocus)
+		}
+	})
+
+	t.Run("focuses the window focused before the summon on hide", func(t *testing.T) {
+		isolateMarker(t)
+
+		// after_hide: previous, the default policy
+		err := state.New().Update(func(st *state.State) {
+			st.SetPreviousFocus(notepad.WindowID, finder.WindowID)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(focusedWorkspace, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{notepad, finder}, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetFocusedWindow().
+				Return(&notepad, nil).
+				Times(1),
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace("ws1").
+				Return([]windows.Window{notepad, finder}, nil).
+				Times(1),
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(finder.WindowID).
+				Return(nil).
+				Times(1),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{
+						WorkspaceName: constants.DefaultScratchpadWorkspaceName,
+					},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil).
+				Times(1),
+			aerospaceClient.GetLayoutMock().EXPECT().
+				SetLayout(
+					[]string{"floating"},
+					layout.SetLayoutOpts{WindowID: &notepad.WindowID},
+				).
+				Return(nil).
+				Times(1),
+		)
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "summon", "--toggle", "Notepad")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, "action=to-scratchpad window_id=1234") {
+			t.Fatalf("expected the window to be hidden, got %q", out)
+		}
+
+		current, err := state.New().Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(current.PreviousFocus) != 0 {
+			t.Fatalf("expected the hidden window to be forgotten, got %v", current.PreviousFocus)
+		}
+	})
+}
+
+func TestSummonTarget(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	t.Run("does not focus the window on a workspace that is not visible", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		slack := windows.Window{
+			AppName:   "Slack",
+			WindowID:  1234,
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+		}
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		aerospaceClient.StubCommand(
+			"list-workspaces --monitor all --visible --json",
+			`[{"workspace":"ws1"}]`,
+		)
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{slack}, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "chat"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &slack.WindowID},
+				).
+				Return(nil),
+		)
+
+		out, err := testutils.CmdExecute(cmd.RootCmd(aerospaceClient), "summon", "--to-workspace", "chat", "Slack")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, "target_workspace=chat") {
+			t.Fatalf("expected the window brought to chat, got %s", out)
+		}
+	})
+
+	t.Run("summons the window to the workspace under the mouse", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		slack := windows.Window{
+			AppName:   "Slack",
+			WindowID:  1234,
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+		}
+
+		aerospaceClient := testutils.NewMockAeroSpaceWM(ctrl)
+		aerospaceClient.StubCommand(
+			"list-monitors --mouse --json",
+			`[{"monitor-id":2,"monitor-name":"DELL"}]`,
+		)
+		aerospaceClient.StubCommand(
+			"list-workspaces --monitor 2 --visible --json",
+			`[{"workspace":"ws2"}]`,
+		)
+		gomock.InOrder(
+			aerospaceClient.GetWindowsMock().EXPECT().
+				GetAllWindows().
+				Return([]windows.Window{slack}, nil),
+			aerospaceClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(
+					workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: "ws2"},
+					workspaces.MoveWindowToWorkspaceOpts{WindowID: &slack.WindowID},
+				).
+				Return(nil),
+			aerospaceClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(slack.WindowID).
+				Return(nil),
+		)
+
+		out, err := testutils.CmdExecute(
+			cmd.RootCmd(aerospaceClient),
+			"summon", "Slack", "--to-monitor", "mouse",
+		)
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+		if !strings.Contains(out, "target_workspace=ws2") || !strings.Contains(out, "monitor=DELL") {
+			t.Fatalf("expected the window summoned to ws2 on DELL, got %s", out)
+		}
+	})
+}
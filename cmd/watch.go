@@ -0,0 +1,214 @@
+/*
+Copyright © 2025 Cristian Oliveira license@cristianoliveira.dev
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/spf13/cobra"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/config"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/eventlog"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+)
+
+// annotationLongRunning marks the commands the global timeout does not
+// bound as a whole.
+const annotationLongRunning = "long-running"
+
+const defaultWatchInterval = time.Second
+
+// WatchCmd represents the watch command.
+func WatchCmd(
+	aerospaceClient aerospace.AeroSpaceWMClient,
+	cfg *config.Config,
+) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "watch",
+		Short: "Stream scratchpad events as newline-delimited JSON",
+		Long: `Stream scratchpad events as newline-delimited JSON, until interrupted.
+
+Every event applied by the commands and hooks, e.g. a window stashed, shown or
+summoned, is streamed as soon as it happens, with the same schema as --output json.
+On top of them, watch reports:
+- a "closed" action when a stashed or shown window is closed
+- a "count" action when the number of stashed windows changes, in the message
+
+The count is also reported on start, e.g. to initialize a status bar.
+The global timeout applies to each query to AeroSpace.
+`,
+		Annotations: map[string]string{annotationLongRunning: "true"},
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			interval, err := cmd.Flags().GetDuration("interval")
+			if err != nil {
+				return fmt.Errorf("unable to get interval flag: %w", err)
+			}
+			if interval <= 0 {
+				return fmt.Errorf("invalid interval: %s", interval)
+			}
+
+			formatter, err := cli.NewOutputFormatter(os.Stdout, string(cli.OutputFormatJSON))
+			if err != nil {
+				return err
+			}
+
+			tail, err := eventlog.NewTail(eventlog.DefaultPath())
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			w := &watcher{
+				client:  aerospace.NewAeroSpaceClient(aerospaceClient),
+				tail:    tail,
+				timeout: commandTimeout(cmd, cfg),
+				count:   -1,
+				logger:  logger.GetDefaultLogger(),
+			}
+			return w.run(ctx, interval, formatter)
+		},
+	}
+
+	command.Flags().Duration(
+		"interval", defaultWatchInterval,
+		"How often to check for new events and closed windows",
+	)
+
+	return command
+}
+
+type watcher struct {
+	client  *aerospace.AeroSpaceClient
+	tail    *eventlog.Tail
+	timeout time.Duration
+	logger  logger.Logger
+
+	// tracked are the stashed and shown windows of the last poll
+	tracked map[int]windows.Window
+	// count is the number of stashed windows of the last poll, -1 before
+	// the first one
+	count int
+}
+
+func (w *watcher) run(
+	ctx context.Context,
+	interval time.Duration,
+	formatter *cli.OutputFormatter,
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, event := range w.poll(ctx) {
+			if err := formatter.Print(event); err != nil {
+				// Nobody is reading anymore, e.g. the status bar exited
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll returns the events published since the last poll, followed by the
+// closed windows and the count change. AeroSpace being unreachable is
+// logged and retried on the next poll.
+func (w *watcher) poll(ctx context.Context) []cli.OutputEvent {
+	events, err := w.tail.Next()
+	if err != nil {
+		w.logger.LogError("WATCH: unable to read events", "error", err)
+	}
+
+	queryCtx := ctx
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		queryCtx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+
+	allWindows, err := w.client.GetAllWindows(queryCtx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.LogError("WATCH: unable to get windows", "error", err)
+		}
+		return events
+	}
+
+	return append(events, w.diff(allWindows)...)
+}
+
+// diff compares the windows with the ones of the last poll.
+func (w *watcher) diff(allWindows []windows.Window) []cli.OutputEvent {
+	current, err := state.New().Load()
+	if err != nil {
+		w.logger.LogError("WATCH: unable to load state", "error", err)
+	}
+
+	var events []cli.OutputEvent
+	tracked := make(map[int]windows.Window)
+	stashed := 0
+	existing := make(map[int]bool, len(allWindows))
+	for _, window := range allWindows {
+		existing[window.WindowID] = true
+
+		isStashed := aerospace.IsScratchpadWorkspace(window.Workspace)
+		if isStashed {
+			stashed++
+		}
+		if isStashed || slices.Contains(current.Sticky, window.WindowID) {
+			tracked[window.WindowID] = window
+		}
+	}
+
+	for _, window := range w.tracked {
+		if existing[window.WindowID] {
+			continue
+		}
+
+		pool := aerospace.PoolOf(window.Workspace)
+		events = append(events, cli.OutputEvent{
+			Command:   "watch",
+			Action:    "closed",
+			Workspace: window.Workspace,
+			Result:    "ok",
+			Pool:      pool,
+		}.WithWindow(window))
+	}
+	// Map order is random, keep the stream stable
+	slices.SortFunc(events, func(a, b cli.OutputEvent) int {
+		return a.WindowID - b.WindowID
+	})
+
+	if stashed != w.count {
+		events = append(events, cli.OutputEvent{
+			Command: "watch",
+			Action:  "count",
+			Result:  "ok",
+			Message: strconv.Itoa(stashed),
+		})
+	}
+
+	w.tracked = tracked
+	w.count = stashed
+
+	return events
+}
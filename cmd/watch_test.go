@@ -0,0 +1,164 @@
+package cmd_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-scratchpad/cmd"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/eventlog"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
+)
+
+func TestWatchCmd(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+
+	t.Run("streams published events, closed windows and count changes", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stashed := []windows.Window{
+			{WindowID: 1, AppName: "Terminal", Workspace: ".scratchpad"},
+			{WindowID: 2, AppName: "Spotify", Workspace: ".scratchpad-pool-media"},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var polls atomic.Int32
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			DoAndReturn(func() ([]windows.Window, error) {
+				switch polls.Add(1) {
+				case 1:
+					return stashed, nil
+				case 2:
+					// Shown by another command
+					if err := eventlog.Append(eventlog.DefaultPath(), []cli.OutputEvent{{
+						Command: "show", Action: "to-workspace", WindowID: 3, Result: "ok",
+					}}); err != nil {
+						t.Errorf("unable to publish event: %v", err)
+					}
+					return stashed, nil
+				case 3:
+					return stashed[:1], nil
+				default:
+					cancel()
+					return stashed[:1], nil
+				}
+			}).
+			MinTimes(4)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		rootCmd.SetContext(ctx)
+		out, err := testutils.CmdExecute(rootCmd, "watch", "--interval", "1ms")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+
+		var actions []string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			var event cli.OutputEvent
+			if err = json.Unmarshal([]byte(line), &event); err != nil {
+				t.Fatalf("expected JSON lines, got %q", out)
+			}
+			actions = append(actions, event.Command+":"+event.Action+":"+event.Message)
+		}
+
+		expected := []string{
+			"watch:count:2",
+			"show:to-workspace:",
+			"watch:closed:",
+			"watch:count:1",
+		}
+		if strings.Join(actions, " ") != strings.Join(expected, " ") {
+			t.Fatalf("expected events %v, got %v", expected, actions)
+		}
+	})
+
+	t.Run("commands publish the events they applied", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		window := windows.Window{WindowID: 7, AppName: "1Password", Workspace: "ws1"}
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return([]windows.Window{window}, nil).
+			Times(2)
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+		mockClient.GetLayoutMock().EXPECT().
+			SetLayout(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		tail, err := eventlog.NewTail(eventlog.DefaultPath())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, dryRun := range []bool{true, false} {
+			args := []string{"hook", "window-detected", "7", "--filter", "app-name=^1Password$"}
+			if dryRun {
+				args = append(args, "--dry-run")
+			}
+			if _, err = testutils.CmdExecute(cmd.RootCmd(mockClient), args...); err != nil {
+				t.Fatalf("expected success, got error %v", err)
+			}
+		}
+
+		// Planned events are never published
+		events, err := tail.Next()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(events) != 1 || events[0].Action != "to-scratchpad" || events[0].Result != "ok" {
+			t.Fatalf("expected the applied event only, got %+v", events)
+		}
+	})
+
+	t.Run("is not bound by the global timeout", func(t *testing.T) {
+		isolateMarker(t)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		started := time.Now()
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			DoAndReturn(func() ([]windows.Window, error) {
+				if time.Since(started) > 50*time.Millisecond {
+					cancel()
+				}
+				return nil, nil
+			}).
+			MinTimes(1)
+
+		rootCmd := cmd.RootCmd(mockClient)
+		rootCmd.SetContext(ctx)
+		_, err := testutils.CmdExecute(rootCmd, "watch", "--interval", "5ms", "--timeout", "10ms")
+		if err != nil {
+			t.Fatalf("expected success, got error %v", err)
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package aerospace
 
 import (
+	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,7 +12,6 @@ import (
 	"strings"
 	"sync"
 
-	aerospacecli "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace"
 	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/focus"
 	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/layout"
 	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
@@ -64,9 +65,9 @@ func extractWindowManagerBinary() (string, error) {
 //
 //revive:disable:exported
 type AeroSpaceClient struct {
-	ogClient *aerospacecli.AeroSpaceWM
-	client   AeroSpaceWMClient // Interface for Windows()/Workspaces() access
-	dryRun   bool
+	conn   *connection // Shared with the wrappers of this client
+	dryRun bool
+	plan   Plan // Operations recorded in dry-run mode
 }
 
 // ClientOpts defines options for creating a new AeroSpaceClient.
@@ -75,16 +76,18 @@ type ClientOpts struct {
 }
 
 // NewAeroSpaceClient creates a new AeroSpaceClient with the default settings.
+//
+// Wrapping an AeroSpaceClient again shares its connection and reconnect
+// settings instead of nesting the wrappers. A nil client is connected later
+// through Connect.
 func NewAeroSpaceClient(client AeroSpaceWMClient) *AeroSpaceClient {
-	// Type assert to get the underlying *AeroSpaceWM for storage
-	var ogClient *aerospacecli.AeroSpaceWM
-	if realClient, ok := client.(*aerospacecli.AeroSpaceWM); ok {
-		ogClient = realClient
+	if wrapped, ok := client.(*AeroSpaceClient); ok {
+		return &AeroSpaceClient{conn: wrapped.conn}
 	}
+
 	return &AeroSpaceClient{
-		ogClient: ogClient,
-		client:   client,
-		dryRun:   false, // Default dry-run is false
+		conn:   newConnection(client),
+		dryRun: false, // Default dry-run is false
 	}
 }
 
@@ -93,63 +96,98 @@ func (c *AeroSpaceClient) SetOptions(opts ClientOpts) {
 	c.dryRun = opts.DryRun
 }
 
+// SetDialer enables transparent reconnection: when an idempotent query fails
+// because the connection broke, e.g. AeroSpace restarted, the client dials
+// again and retries the query once.
+func (c *AeroSpaceClient) SetDialer(dial Dialer) {
+	c.conn.setDialer(dial)
+}
+
 // Windows returns the windows service.
 func (c *AeroSpaceClient) Windows() *windows.Service {
-	return c.client.Windows()
+	return c.GetUnderlyingClient().Windows()
 }
 
 // Workspaces returns the workspaces service.
 func (c *AeroSpaceClient) Workspaces() *workspaces.Service {
-	return c.client.Workspaces()
+	return c.GetUnderlyingClient().Workspaces()
 }
 
 // Focus returns the focus service.
 func (c *AeroSpaceClient) Focus() *focus.Service {
-	return c.client.Focus()
+	return c.GetUnderlyingClient().Focus()
 }
 
 // Layout returns the layout service.
 func (c *AeroSpaceClient) Layout() *layout.Service {
-	return c.client.Layout()
+	return c.GetUnderlyingClient().Layout()
 }
 
 // GetAllWindows retrieves all windows managed by AeroSpaceWM.
-func (c *AeroSpaceClient) GetAllWindows() ([]windows.Window, error) {
-	return c.client.Windows().GetAllWindows()
+func (c *AeroSpaceClient) GetAllWindows(
+	ctx context.Context,
+) ([]windows.Window, error) {
+	return query(ctx, c, func(cli AeroSpaceWMClient) ([]windows.Window, error) {
+		return cli.Windows().GetAllWindows()
+	})
 }
 
 func (c *AeroSpaceClient) GetAllWindowsByWorkspace(
+	ctx context.Context,
 	workspaceName string,
 ) ([]windows.Window, error) {
-	return c.client.Windows().GetAllWindowsByWorkspace(workspaceName)
+	return query(ctx, c, func(cli AeroSpaceWMClient) ([]windows.Window, error) {
+		return cli.Windows().GetAllWindowsByWorkspace(workspaceName)
+	})
 }
 
-func (c *AeroSpaceClient) GetFocusedWindow() (*windows.Window, error) {
-	return c.client.Windows().GetFocusedWindow()
+func (c *AeroSpaceClient) GetFocusedWindow(
+	ctx context.Context,
+) (*windows.Window, error) {
+	return query(ctx, c, func(cli AeroSpaceWMClient) (*windows.Window, error) {
+		return cli.Windows().GetFocusedWindow()
+	})
 }
 
-func (c *AeroSpaceClient) SetFocusByWindowID(windowID int) error {
+func (c *AeroSpaceClient) SetFocusByWindowID(
+	ctx context.Context,
+	windowID int,
+) error {
 	if c.dryRun {
-		fmt.Fprintf(os.Stdout, "[dry-run] SetFocusByWindowID(%d)\n", windowID)
+		c.record(PlannedOperation{
+			Action:   PlanActionFocus,
+			WindowID: windowID,
+		})
 		return nil
 	}
-	return c.client.Focus().SetFocusByWindowID(windowID)
+	cli, gate := c.conn.current()
+	return withContextErr(ctx, gate, func() error {
+		return cli.Focus().SetFocusByWindowID(windowID)
+	})
 }
 
 // FocusNextTilingWindow moves focus to the next tiled window in depth-first order, ignoring floating windows.
 // Equivalent to: `aerospace focus dfs-next --ignore-floating`.
-func (c *AeroSpaceClient) FocusNextTilingWindow() error {
+func (c *AeroSpaceClient) FocusNextTilingWindow(ctx context.Context) error {
 	if c.dryRun {
-		fmt.Fprintln(os.Stdout, "[dry-run] FocusNextTilingWindow()")
+		c.record(PlannedOperation{Action: PlanActionFocusNextTiling})
 		return nil
 	}
-	err := c.client.Focus().SetFocusByDFS("dfs-next", focus.SetFocusOpts{
-		IgnoreFloating: true,
+	cli, gate := c.conn.current()
+	err := withContextErr(ctx, gate, func() error {
+		return cli.Focus().SetFocusByDFS("dfs-next", focus.SetFocusOpts{
+			IgnoreFloating: true,
+		})
 	})
+	if isContextError(err) {
+		return err
+	}
 	if err != nil {
 		// Try dfs-prev if dfs-next fails
-		err = c.client.Focus().SetFocusByDFS("dfs-prev", focus.SetFocusOpts{
-			IgnoreFloating: true,
+		err = withContextErr(ctx, gate, func() error {
+			return cli.Focus().SetFocusByDFS("dfs-prev", focus.SetFocusOpts{
+				IgnoreFloating: true,
+			})
 		})
 		if err != nil {
 			return fmt.Errorf("failed to focus next tiling window: %w", err)
@@ -159,67 +197,144 @@ func (c *AeroSpaceClient) FocusNextTilingWindow() error {
 	return nil
 }
 
-func (c *AeroSpaceClient) GetFocusedWorkspace() (*workspaces.Workspace, error) {
-	return c.client.Workspaces().GetFocusedWorkspace()
+func (c *AeroSpaceClient) GetFocusedWorkspace(
+	ctx context.Context,
+) (*workspaces.Workspace, error) {
+	return query(ctx, c, func(cli AeroSpaceWMClient) (*workspaces.Workspace, error) {
+		return cli.Workspaces().GetFocusedWorkspace()
+	})
 }
 
 func (c *AeroSpaceClient) MoveWindowToWorkspace(
+	ctx context.Context,
 	windowID int,
 	workspaceName string,
 ) error {
 	if c.dryRun {
-		fmt.Fprintf(
-			os.Stdout,
-			"[dry-run] MoveWindowToWorkspace(windowID=%d, workspace=%s)\n",
-			windowID,
-			workspaceName,
+		c.record(PlannedOperation{
+			Action:    PlanActionToWorkspace,
+			WindowID:  windowID,
+			Workspace: workspaceName,
+		})
+		return nil
+	}
+	cli, gate := c.conn.current()
+	return withContextErr(ctx, gate, func() error {
+		return cli.Workspaces().MoveWindowToWorkspaceWithOpts(
+			workspaces.MoveWindowToWorkspaceArgs{
+				WorkspaceName: workspaceName,
+			},
+			workspaces.MoveWindowToWorkspaceOpts{
+				WindowID: &windowID,
+			},
 		)
+	})
+}
+
+func (c *AeroSpaceClient) SetLayout(
+	ctx context.Context,
+	windowID int,
+	layoutName string,
+) error {
+	if c.dryRun {
+		c.record(PlannedOperation{
+			Action:   PlanActionLayout,
+			WindowID: windowID,
+			Detail:   layoutName,
+		})
 		return nil
 	}
-	return c.client.Workspaces().MoveWindowToWorkspaceWithOpts(
-		workspaces.MoveWindowToWorkspaceArgs{
-			WorkspaceName: workspaceName,
-		},
-		workspaces.MoveWindowToWorkspaceOpts{
-			WindowID: &windowID,
+	cli, gate := c.conn.current()
+	return withContextErr(ctx, gate, func() error {
+		return cli.Layout().SetLayout([]string{layoutName}, layout.SetLayoutOpts{
+			WindowID: layout.IntPtr(windowID),
+		})
+	})
+}
+
+// MoveWindowToWorkspaceAndFocus moves a window to a workspace and focuses it
+// there, equivalent to `aerospace move-node-to-workspace --focus-follows-window`.
+func (c *AeroSpaceClient) MoveWindowToWorkspaceAndFocus(
+	ctx context.Context,
+	windowID int,
+	workspaceName string,
+) error {
+	if c.dryRun {
+		c.record(PlannedOperation{
+			Action:    PlanActionToWorkspace,
+			WindowID:  windowID,
+			Workspace: workspaceName,
+			Detail:    "focus-follows-window",
+		})
+		return nil
+	}
+
+	response, err := c.SendCommand(
+		ctx,
+		"move-node-to-workspace",
+		[]string{
+			workspaceName,
+			"--window-id", strconv.Itoa(windowID),
+			"--focus-follows-window",
 		},
 	)
+	if err != nil {
+		return err
+	}
+	if response.ExitCode != 0 {
+		return errors.New(response.StdErr)
+	}
+
+	return nil
 }
 
-func (c *AeroSpaceClient) SetLayout(windowID int, layoutName string) error {
+// CloseWindow closes a window, equivalent to `aerospace close --window-id`.
+func (c *AeroSpaceClient) CloseWindow(ctx context.Context, windowID int) error {
 	if c.dryRun {
-		fmt.Fprintf(
-			os.Stdout,
-			"[dry-run] SetLayout(windowID=%d, layout=%s)\n",
-			windowID,
-			layoutName,
-		)
+		c.record(PlannedOperation{
+			Action:   PlanActionClose,
+			WindowID: windowID,
+		})
 		return nil
 	}
-	return c.client.Layout().SetLayout([]string{layoutName}, layout.SetLayoutOpts{
-		WindowID: layout.IntPtr(windowID),
+
+	response, err := c.SendCommand(
+		ctx,
+		"close",
+		[]string{"--window-id", strconv.Itoa(windowID)},
+	)
+	if err != nil {
+		return err
+	}
+	if response.ExitCode != 0 {
+		return errors.New(response.StdErr)
+	}
+
+	return nil
+}
+
+// SendCommand sends a raw command to AeroSpace, for commands that have no
+// service in aerospace-ipc. It is not covered by dry-run.
+func (c *AeroSpaceClient) SendCommand(
+	ctx context.Context,
+	command string,
+	args []string,
+) (*client.Response, error) {
+	cli, gate := c.conn.current()
+	return withContext(ctx, gate, func() (*client.Response, error) {
+		return cli.Connection().SendCommand(command, args)
 	})
 }
 
 func (c *AeroSpaceClient) Connection() client.AeroSpaceConnection {
-	if c.client != nil {
-		return c.client.Connection()
-	}
-	return c.ogClient.Connection()
+	return c.GetUnderlyingClient().Connection()
 }
 
 func (c *AeroSpaceClient) CloseConnection() error {
-	if c.dryRun {
-		fmt.Fprintln(os.Stdout, "[dry-run] CloseConnection()")
-		return nil
+	if closer, ok := c.GetUnderlyingClient().(interface{ CloseConnection() error }); ok {
+		return closer.CloseConnection()
 	}
-	if c.client != nil {
-		if closer, ok := c.client.(interface{ CloseConnection() error }); ok {
-			return closer.CloseConnection()
-		}
-		return nil
-	}
-	return c.ogClient.CloseConnection()
+	return nil
 }
 
 // AeroSpaceWMClient defines the interface for clients that provide Windows(), Workspaces(), Focus(), and Layout() services.
@@ -234,10 +349,8 @@ type AeroSpaceWMClient interface {
 // GetUnderlyingClient returns the underlying AeroSpaceWM client.
 // This is needed for components that need direct access to Windows() and Workspaces() methods.
 func (c *AeroSpaceClient) GetUnderlyingClient() AeroSpaceWMClient {
-	if c.client != nil {
-		return c.client
-	}
-	return c.ogClient
+	cli, _ := c.conn.current()
+	return cli
 }
 
 // ExtendedAeroSpaceClient wraps AeroSpaceClient with additional functionality
@@ -253,7 +366,20 @@ func NewExtendedAeroSpaceClient(baseClient *AeroSpaceClient) *ExtendedAeroSpaceC
 }
 
 // SetFullscreen sets fullscreen mode for a window
-func (c *ExtendedAeroSpaceClient) SetFullscreen(windowID int, enabled bool) error {
+func (c *ExtendedAeroSpaceClient) SetFullscreen(ctx context.Context, windowID int, enabled bool) error {
+	if c.dryRun {
+		detail := "off"
+		if enabled {
+			detail = "on"
+		}
+		c.record(PlannedOperation{
+			Action:   PlanActionFullscreen,
+			WindowID: windowID,
+			Detail:   detail,
+		})
+		return nil
+	}
+
 	cmd := []string{"aerospace", "fullscreen"}
 	if enabled {
 		cmd = append(cmd, "on")
@@ -262,7 +388,7 @@ func (c *ExtendedAeroSpaceClient) SetFullscreen(windowID int, enabled bool) erro
 	}
 	cmd = append(cmd, "--window-id", strconv.Itoa(windowID))
 
-	execCmd := exec.Command(cmd[0], cmd[1:]...)
+	execCmd := exec.CommandContext(ctx, cmd[0], cmd[1:]...)
 	output, err := execCmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to set fullscreen: %w, output: %s", err, output)
@@ -271,8 +397,8 @@ func (c *ExtendedAeroSpaceClient) SetFullscreen(windowID int, enabled bool) erro
 }
 
 // GetScreenDimensions gets the primary screen dimensions
-func (c *ExtendedAeroSpaceClient) GetScreenDimensions() (int, int, error) {
-	cmd := exec.Command("system_profiler", "SPDisplaysDataType")
+func (c *ExtendedAeroSpaceClient) GetScreenDimensions(ctx context.Context) (int, int, error) {
+	cmd := exec.CommandContext(ctx, "system_profiler", "SPDisplaysDataType")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to get screen info: %w", err)
@@ -300,24 +426,33 @@ func (c *ExtendedAeroSpaceClient) GetScreenDimensions() (int, int, error) {
 }
 
 // ResizeToPercentage resizes a window to specific percentage of screen using Swift window manager
-func (c *ExtendedAeroSpaceClient) ResizeToPercentage(windowID int, widthPercent, heightPercent int) error {
-	return c.ResizeToPercentageWithPosition(windowID, widthPercent, heightPercent, "center")
+func (c *ExtendedAeroSpaceClient) ResizeToPercentage(ctx context.Context, windowID int, widthPercent, heightPercent int) error {
+	return c.ResizeToPercentageWithPosition(ctx, windowID, widthPercent, heightPercent, "center")
 }
 
 // ResizeToPercentageWithPosition resizes and positions a window using Swift window manager
-func (c *ExtendedAeroSpaceClient) ResizeToPercentageWithPosition(windowID int, widthPercent, heightPercent int, position string) error {
+func (c *ExtendedAeroSpaceClient) ResizeToPercentageWithPosition(ctx context.Context, windowID int, widthPercent, heightPercent int, position string) error {
 	// Focus the window first to ensure it's active
-	if err := c.SetFocusByWindowID(windowID); err != nil {
+	if err := c.SetFocusByWindowID(ctx, windowID); err != nil {
 		fmt.Printf("Warning: failed to focus window %d before resizing: %v\n", windowID, err)
 	}
 
+	if c.dryRun {
+		c.record(PlannedOperation{
+			Action:   PlanActionResize,
+			WindowID: windowID,
+			Detail:   fmt.Sprintf("%d%%x%d%%@%s", widthPercent, heightPercent, position),
+		})
+		return nil
+	}
+
 	// Extract the embedded Swift window manager binary
 	windowManagerPath, err := extractWindowManagerBinary()
 	if err != nil {
 		return fmt.Errorf("failed to extract window manager binary: %w", err)
 	}
 
-	cmd := exec.Command(windowManagerPath, "resize", strconv.Itoa(windowID), strconv.Itoa(widthPercent), strconv.Itoa(heightPercent), position)
+	cmd := exec.CommandContext(ctx, windowManagerPath, "resize", strconv.Itoa(windowID), strconv.Itoa(widthPercent), strconv.Itoa(heightPercent), position)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -331,8 +466,16 @@ func (c *ExtendedAeroSpaceClient) ResizeToPercentageWithPosition(windowID int, w
 }
 
 // CenterWindow centers a window using move-mouse command
-func (c *ExtendedAeroSpaceClient) CenterWindow(windowID int) error {
-	cmd := exec.Command("aerospace", "move-mouse", "window-force-center", "--window-id", strconv.Itoa(windowID))
+func (c *ExtendedAeroSpaceClient) CenterWindow(ctx context.Context, windowID int) error {
+	if c.dryRun {
+		c.record(PlannedOperation{
+			Action:   PlanActionCenter,
+			WindowID: windowID,
+		})
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, "aerospace", "move-mouse", "window-force-center", "--window-id", strconv.Itoa(windowID))
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to center window: %w, output: %s", err, output)
@@ -397,7 +540,7 @@ func ParseGeometry(geometry string) (*GeometrySpec, error) {
 }
 
 // ApplyGeometry applies geometry to a window
-func (c *ExtendedAeroSpaceClient) ApplyGeometry(windowID int, geometry string) error {
+func (c *ExtendedAeroSpaceClient) ApplyGeometry(ctx context.Context, windowID int, geometry string) error {
 	spec, err := ParseGeometry(geometry)
 	if err != nil {
 		return err
@@ -405,13 +548,13 @@ func (c *ExtendedAeroSpaceClient) ApplyGeometry(windowID int, geometry string) e
 
 	// Try to set floating mode, but don't fail if it doesn't work
 	// Some windows (like Arc) might not support floating mode
-	if err := c.SetLayout(windowID, "floating"); err != nil {
+	if err := c.SetLayout(ctx, windowID, "floating"); err != nil {
 		fmt.Printf("Info: Could not set floating layout for window %d, continuing anyway\n", windowID)
 	}
 
 	// Resize and position the window using percentage-based sizing
 	// This should work regardless of floating mode
-	if err := c.ResizeToPercentageWithPosition(windowID, spec.WidthPercent, spec.HeightPercent, spec.Position); err != nil {
+	if err := c.ResizeToPercentageWithPosition(ctx, windowID, spec.WidthPercent, spec.HeightPercent, spec.Position); err != nil {
 		return fmt.Errorf("failed to resize window to percentage: %w", err)
 	}
 
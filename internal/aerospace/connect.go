@@ -0,0 +1,217 @@
+package aerospace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	aerospacecli "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+)
+
+// ErrNotRunning is returned when the AeroSpace socket cannot be reached.
+var ErrNotRunning = errors.New("AeroSpace is not running")
+
+// Dialer opens a new connection to AeroSpace.
+type Dialer func() (AeroSpaceWMClient, error)
+
+// DefaultDialer connects to the AeroSpace socket, see aerospace-ipc NewClient.
+func DefaultDialer() (AeroSpaceWMClient, error) {
+	client, err := aerospacecli.NewClient()
+	if err != nil {
+		// Avoid returning a typed nil inside the interface
+		return nil, err
+	}
+	return client, nil
+}
+
+// RetryPolicy bounds how long to wait for AeroSpace to accept connections.
+type RetryPolicy struct {
+	// Attempts is the total number of connection attempts
+	Attempts int
+	// InitialBackoff is the wait after the first failure, doubled after each one
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between two attempts
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy waits up to ~1.5s, enough for AeroSpace to come back
+// after a `reload-config`.
+//
+//nolint:gochecknoglobals // shared read-only default
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:       5,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     time.Second,
+}
+
+// Connect dials AeroSpace, retrying with exponential backoff while it is not
+// reachable.
+//
+// Returns ErrNotRunning when every attempt failed.
+func Connect(
+	ctx context.Context,
+	dial Dialer,
+	policy RetryPolicy,
+) (AeroSpaceWMClient, error) {
+	logger := logger.GetDefaultLogger()
+
+	attempts := max(policy.Attempts, 1)
+	backoff := policy.InitialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		client, err := dial()
+		if err == nil {
+			return client, nil
+		}
+		lastErr = err
+		logger.LogDebug(
+			"CONNECT: unable to connect to AeroSpace",
+			"attempt", attempt,
+			"error", err,
+		)
+
+		if attempt == attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ErrNotRunning, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, policy.MaxBackoff)
+	}
+
+	return nil, fmt.Errorf("%w: %w", ErrNotRunning, lastErr)
+}
+
+// isConnectionError reports whether err means the socket connection broke,
+// e.g. because AeroSpace restarted, in which case a new connection may work.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	return errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		strings.Contains(err.Error(), "connection is not established")
+}
+
+// connection is the AeroSpace connection shared by every wrapper of a
+// client, so a reconnect through one of them is seen by all of them.
+type connection struct {
+	mu     sync.Mutex
+	client AeroSpaceWMClient
+	gate   callGate // Serializes the calls on client
+	dial   Dialer   // Reconnects idempotent queries when set
+}
+
+func newConnection(client AeroSpaceWMClient) *connection {
+	return &connection{client: client, gate: newCallGate()}
+}
+
+// current returns the client and the gate its calls go through.
+func (c *connection) current() (AeroSpaceWMClient, callGate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client, c.gate
+}
+
+func (c *connection) dialer() Dialer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dial
+}
+
+func (c *connection) setDialer(dial Dialer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dial = dial
+}
+
+// replace swaps in a new client, calls still running on the old one keep
+// holding its gate.
+func (c *connection) replace(client AeroSpaceWMClient) AeroSpaceWMClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	old := c.client
+	c.client = client
+	c.gate = newCallGate()
+	return old
+}
+
+// Connect dials AeroSpace with the dialer set by SetDialer when the client
+// has no connection yet, retrying as the Connect function does.
+func (c *AeroSpaceClient) Connect(ctx context.Context, policy RetryPolicy) error {
+	if c.GetUnderlyingClient() != nil {
+		return nil
+	}
+
+	dial := c.conn.dialer()
+	if dial == nil {
+		return fmt.Errorf("%w: no dialer set", ErrNotRunning)
+	}
+	client, err := Connect(ctx, dial, policy)
+	if err != nil {
+		return err
+	}
+
+	c.conn.replace(client)
+	return nil
+}
+
+// query runs an idempotent query, reconnecting once and retrying when the
+// connection broke and the client knows how to dial again.
+func query[T any](
+	ctx context.Context,
+	c *AeroSpaceClient,
+	fn func(client AeroSpaceWMClient) (T, error),
+) (T, error) {
+	client, gate := c.conn.current()
+	value, err := withContext(ctx, gate, func() (T, error) {
+		return fn(client)
+	})
+	if c.conn.dialer() == nil || !isConnectionError(err) {
+		return value, err
+	}
+
+	logger := logger.GetDefaultLogger()
+	logger.LogInfo("CONNECT: connection lost, reconnecting", "error", err)
+	if reconnectErr := c.reconnect(); reconnectErr != nil {
+		logger.LogError("CONNECT: unable to reconnect", "error", reconnectErr)
+		return value, err
+	}
+
+	client, gate = c.conn.current()
+	return withContext(ctx, gate, func() (T, error) {
+		return fn(client)
+	})
+}
+
+// reconnect replaces the shared connection with a fresh one.
+func (c *AeroSpaceClient) reconnect() error {
+	client, err := c.conn.dialer()()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotRunning, err)
+	}
+
+	old := c.conn.replace(client)
+	if closer, ok := old.(interface{ CloseConnection() error }); ok {
+		// The old connection is broken already, nothing to do on failure
+		_ = closer.CloseConnection()
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package aerospace_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
+)
+
+func TestConnect(t *testing.T) {
+	ctx := context.Background()
+	policy := aerospace.RetryPolicy{
+		Attempts:       3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	}
+
+	t.Run("retries until AeroSpace accepts the connection", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		calls := 0
+		client, err := aerospace.Connect(ctx, func() (aerospace.AeroSpaceWMClient, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("connection refused")
+			}
+			return mockClient, nil
+		}, policy)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if client != mockClient || calls != 3 {
+			t.Fatalf("expected mock client after 3 attempts, got %v after %d", client, calls)
+		}
+	})
+
+	t.Run("returns ErrNotRunning when every attempt fails", func(t *testing.T) {
+		calls := 0
+		client, err := aerospace.Connect(ctx, func() (aerospace.AeroSpaceWMClient, error) {
+			calls++
+			return nil, errors.New("connection refused")
+		}, policy)
+		if !errors.Is(err, aerospace.ErrNotRunning) {
+			t.Fatalf("expected ErrNotRunning, got %v", err)
+		}
+		if client != nil || calls != policy.Attempts {
+			t.Fatalf("expected no client after %d attempts, got %v after %d", policy.Attempts, client, calls)
+		}
+	})
+}
+
+func TestAeroSpaceClientReconnect(t *testing.T) {
+	ctx := context.Background()
+	brokenPipe := &net.OpError{Op: "write", Net: "unix", Err: syscall.EPIPE}
+
+	t.Run("retries idempotent queries on a new connection", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		brokenClient := testutils.NewMockAeroSpaceWM(ctrl)
+		brokenClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(nil, brokenPipe).
+			Times(1)
+
+		freshClient := testutils.NewMockAeroSpaceWM(ctrl)
+		freshClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return([]windows.Window{{WindowID: 1}}, nil).
+			Times(2)
+
+		client := aerospace.NewAeroSpaceClient(brokenClient)
+		client.SetDialer(func() (aerospace.AeroSpaceWMClient, error) {
+			return freshClient, nil
+		})
+
+		wins, err := client.GetAllWindows(ctx)
+		if err != nil || len(wins) != 1 {
+			t.Fatalf("expected 1 window, got %v err=%v", wins, err)
+		}
+
+		// Following calls keep using the new connection
+		if _, err = client.GetAllWindows(ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("does not reconnect on command errors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(nil, errors.New("command failed")).
+			Times(1)
+
+		client := aerospace.NewAeroSpaceClient(mockClient)
+		client.SetDialer(func() (aerospace.AeroSpaceWMClient, error) {
+			t.Fatalf("unexpected reconnect")
+			return nil, nil
+		})
+
+		if _, err := client.GetAllWindows(ctx); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("returns the original error when reconnecting fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(nil, brokenPipe).
+			Times(1)
+
+		client := aerospace.NewAeroSpaceClient(mockClient)
+		client.SetDialer(func() (aerospace.AeroSpaceWMClient, error) {
+			return nil, errors.New("connection refused")
+		})
+
+		if _, err := client.GetFocusedWindow(ctx); !errors.Is(err, syscall.EPIPE) {
+			t.Fatalf("expected broken pipe error, got %v", err)
+		}
+	})
+
+	t.Run("shares the new connection with the other wrappers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		brokenClient := testutils.NewMockAeroSpaceWM(ctrl)
+		brokenClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(nil, brokenPipe).
+			Times(1)
+
+		freshClient := testutils.NewMockAeroSpaceWM(ctrl)
+		freshClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return([]windows.Window{{WindowID: 1}}, nil).
+			Times(2)
+
+		client := aerospace.NewAeroSpaceClient(brokenClient)
+		client.SetDialer(func() (aerospace.AeroSpaceWMClient, error) {
+			return freshClient, nil
+		})
+		wrapper := aerospace.NewAeroSpaceClient(client)
+
+		if _, err := wrapper.GetAllWindows(ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		// The original client uses the connection dialed by the wrapper
+		if _, err := client.GetAllWindows(ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
+func TestAeroSpaceClientConnect(t *testing.T) {
+	ctx := context.Background()
+	policy := aerospace.RetryPolicy{Attempts: 1}
+
+	t.Run("connects a client created without a connection", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return([]windows.Window{{WindowID: 1}}, nil).
+			Times(1)
+
+		calls := 0
+		client := aerospace.NewAeroSpaceClient(nil)
+		client.SetDialer(func() (aerospace.AeroSpaceWMClient, error) {
+			calls++
+			return mockClient, nil
+		})
+		wrapper := aerospace.NewAeroSpaceClient(client)
+
+		if err := client.Connect(ctx, policy); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		// Already connected, nothing to dial
+		if err := wrapper.Connect(ctx, policy); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if calls != 1 {
+			t.Fatalf("expected 1 dial, got %d", calls)
+		}
+
+		if _, err := wrapper.GetAllWindows(ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("returns ErrNotRunning when AeroSpace is not reachable", func(t *testing.T) {
+		client := aerospace.NewAeroSpaceClient(nil)
+		client.SetDialer(func() (aerospace.AeroSpaceWMClient, error) {
+			return nil, errors.New("connection refused")
+		})
+
+		if err := client.Connect(ctx, policy); !errors.Is(err, aerospace.ErrNotRunning) {
+			t.Fatalf("expected ErrNotRunning, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package aerospace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTimeout is returned when AeroSpace does not answer before the context
+// deadline, e.g. when it hangs or the socket stops responding.
+var ErrTimeout = errors.New("timed out waiting for AeroSpace")
+
+// callGate serializes the calls sent on one connection.
+//
+// A call abandoned on timeout holds the gate until AeroSpace answers it, so
+// no later command, e.g. a rollback, is sent before the abandoned one lands.
+type callGate chan struct{}
+
+func newCallGate() callGate {
+	return make(callGate, 1)
+}
+
+// acquire waits for the previous call to finish, or for ctx to be done.
+// A nil gate lets every call through.
+func (g callGate) acquire(ctx context.Context) error {
+	if g == nil {
+		return nil
+	}
+	if ctx == nil {
+		g <- struct{}{}
+		return nil
+	}
+
+	select {
+	case g <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return contextError(ctx)
+	}
+}
+
+func (g callGate) release() {
+	if g != nil {
+		<-g
+	}
+}
+
+// withContext runs fn through the gate and stops waiting for it as soon as
+// ctx is done.
+//
+// The IPC calls cannot be interrupted, so on cancellation fn keeps running in
+// the background, holding the gate, and its result is discarded.
+func withContext[T any](
+	ctx context.Context,
+	gate callGate,
+	fn func() (T, error),
+) (T, error) {
+	var zero T
+	if ctx != nil {
+		if err := contextError(ctx); err != nil {
+			return zero, err
+		}
+	}
+	if err := gate.acquire(ctx); err != nil {
+		return zero, err
+	}
+	if ctx == nil {
+		defer gate.release()
+		return fn()
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	// Buffered so the goroutine can finish even when nobody is waiting
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		gate.release()
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		return zero, contextError(ctx)
+	}
+}
+
+// withContextErr is withContext for calls that only return an error.
+func withContextErr(ctx context.Context, gate callGate, fn func() error) error {
+	_, err := withContext(ctx, gate, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// contextError converts the context error, wrapping deadlines in ErrTimeout.
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+// isContextError reports whether err comes from a done context, errors that
+// must stop the operation instead of being tolerated.
+func isContextError(err error) bool {
+	return errors.Is(err, ErrTimeout) || errors.Is(err, context.Canceled)
+}
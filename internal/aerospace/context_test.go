@@ -0,0 +1,60 @@
+package aerospace_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
+)
+
+func TestAeroSpaceClientTimeout(t *testing.T) {
+	t.Run("does not send commands while a timed out call is in flight", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		release := make(chan struct{})
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			DoAndReturn(func() ([]windows.Window, error) {
+				<-release
+				return nil, nil
+			}).
+			Times(1)
+
+		client := aerospace.NewAeroSpaceClient(mockClient)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := client.GetAllWindows(ctx); !errors.Is(err, aerospace.ErrTimeout) {
+			t.Fatalf("expected ErrTimeout, got %v", err)
+		}
+
+		// A rollback with its own deadline must not overtake the abandoned call
+		rollbackCtx, rollbackCancel := context.WithTimeout(
+			context.Background(),
+			10*time.Millisecond,
+		)
+		defer rollbackCancel()
+		err := client.MoveWindowToWorkspace(rollbackCtx, 1, "ws1")
+		if !errors.Is(err, aerospace.ErrTimeout) {
+			t.Fatalf("expected ErrTimeout, got %v", err)
+		}
+
+		// Once AeroSpace answers the abandoned call the connection is usable again
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+		close(release)
+		if err = client.MoveWindowToWorkspace(context.Background(), 1, "ws1"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
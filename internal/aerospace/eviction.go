@@ -0,0 +1,109 @@
+package aerospace
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+)
+
+// Eviction policies, the first value of each is the default.
+const (
+	// EvictOldestStashed evicts the windows stashed the longest ago
+	EvictOldestStashed = "oldest-stashed"
+	// EvictLeastRecentlyShown evicts the windows shown the longest ago,
+	// windows never shown first
+	EvictLeastRecentlyShown = "least-recently-shown"
+
+	// EvictReturn sends the evicted window back to the workspace it was
+	// stashed from
+	EvictReturn = "return"
+	// EvictClose closes the evicted window
+	EvictClose = "close"
+	// EvictTile sends the evicted window back to the workspace it was stashed
+	// from as a tiling window
+	EvictTile = "tile"
+)
+
+// EvictionPolicy bounds the number of windows of each scratchpad. Empty
+// fields mean the default, a zero MaxWindows disables the eviction.
+type EvictionPolicy struct {
+	// MaxWindows is the most windows a scratchpad holds
+	MaxWindows int
+	// Evict selects the windows evicted: oldest-stashed or least-recently-shown
+	Evict string
+	// Action is what happens to them: return, close or tile
+	Action string
+}
+
+// Validate makes sure the policy values are known.
+func (p EvictionPolicy) Validate() error {
+	if p.MaxWindows < 0 {
+		return errors.New("invalid max windows, must not be negative")
+	}
+
+	for _, policy := range []struct {
+		name    string
+		value   string
+		allowed []string
+	}{
+		{"evict", p.Evict, []string{EvictOldestStashed, EvictLeastRecentlyShown}},
+		{"eviction action", p.Action, []string{EvictReturn, EvictClose, EvictTile}},
+	} {
+		if policy.value != "" && !slices.Contains(policy.allowed, policy.value) {
+			return fmt.Errorf(
+				"invalid %s %q, must be one of: %s",
+				policy.name,
+				policy.value,
+				strings.Join(policy.allowed, ", "),
+			)
+		}
+	}
+	return nil
+}
+
+// Enabled reports whether the policy evicts windows at all.
+func (p EvictionPolicy) Enabled() bool {
+	return p.MaxWindows > 0
+}
+
+// StashTimes are when a scratchpad window was stashed and last shown, zero
+// when unknown.
+type StashTimes struct {
+	StashedAt time.Time
+	ShownAt   time.Time
+}
+
+// PlanEviction returns the windows of a scratchpad to evict so it holds no
+// more than MaxWindows, in eviction order. The kept windows, e.g. the ones
+// just stashed, count towards the limit but are never evicted. Windows
+// without known times go first.
+func PlanEviction(
+	policy EvictionPolicy,
+	stashed []windows.Window,
+	times map[int]StashTimes,
+	kept []int,
+) []windows.Window {
+	excess := len(stashed) - policy.MaxWindows
+	if !policy.Enabled() || excess <= 0 {
+		return nil
+	}
+
+	candidates := slices.DeleteFunc(slices.Clone(stashed), func(window windows.Window) bool {
+		return slices.Contains(kept, window.WindowID)
+	})
+	slices.SortStableFunc(candidates, func(a, b windows.Window) int {
+		timesA, timesB := times[a.WindowID], times[b.WindowID]
+		if policy.Evict == EvictLeastRecentlyShown {
+			if order := timesA.ShownAt.Compare(timesB.ShownAt); order != 0 {
+				return order
+			}
+		}
+		return timesA.StashedAt.Compare(timesB.StashedAt)
+	})
+
+	return candidates[:min(excess, len(candidates))]
+}
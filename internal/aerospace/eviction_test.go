@@ -0,0 +1,72 @@
+package aerospace_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+)
+
+func TestPlanEviction(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	stashed := []windows.Window{
+		{WindowID: 1, Workspace: ".scratchpad"},
+		{WindowID: 2, Workspace: ".scratchpad"},
+		{WindowID: 3, Workspace: ".scratchpad"},
+		{WindowID: 4, Workspace: ".scratchpad"},
+	}
+	times := map[int]aerospace.StashTimes{
+		1: {StashedAt: now.Add(-3 * time.Hour), ShownAt: now.Add(-time.Minute)},
+		2: {StashedAt: now.Add(-2 * time.Hour), ShownAt: now.Add(-time.Hour)},
+		3: {StashedAt: now.Add(-time.Hour)},
+		4: {StashedAt: now},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		policy   aerospace.EvictionPolicy
+		kept     []int
+		expected []int
+	}{
+		{"disabled", aerospace.EvictionPolicy{}, nil, nil},
+		{"under the limit", aerospace.EvictionPolicy{MaxWindows: 4}, nil, nil},
+		{"oldest stashed", aerospace.EvictionPolicy{MaxWindows: 2}, nil, []int{1, 2}},
+		{
+			"least recently shown, never shown first",
+			aerospace.EvictionPolicy{MaxWindows: 2, Evict: aerospace.EvictLeastRecentlyShown},
+			nil,
+			[]int{3, 4},
+		},
+		{"never the kept windows", aerospace.EvictionPolicy{MaxWindows: 2}, []int{1}, []int{2, 3}},
+		{"only the windows not kept", aerospace.EvictionPolicy{MaxWindows: 1}, []int{2, 3, 4}, []int{1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var evicted []int
+			for _, window := range aerospace.PlanEviction(tc.policy, stashed, times, tc.kept) {
+				evicted = append(evicted, window.WindowID)
+			}
+
+			if !reflect.DeepEqual(evicted, tc.expected) {
+				t.Errorf("expected %v evicted, got %v", tc.expected, evicted)
+			}
+		})
+	}
+}
+
+func TestEvictionPolicyValidate(t *testing.T) {
+	for _, policy := range []aerospace.EvictionPolicy{
+		{MaxWindows: -1},
+		{Evict: "newest"},
+		{Action: "minimize"},
+	} {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("expected error for %+v", policy)
+		}
+	}
+
+	if err := (aerospace.EvictionPolicy{MaxWindows: 3, Action: aerospace.EvictTile}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
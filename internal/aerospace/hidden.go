@@ -0,0 +1,45 @@
+package aerospace
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// NeverFocusWorkspaces matches the hidden workspaces that must never keep
+// the focus: every scratchpad workspace plus user defined patterns, e.g.
+// stash areas.
+type NeverFocusWorkspaces struct {
+	patterns []*regexp.Regexp
+}
+
+// NewNeverFocusWorkspaces compiles the extra workspace patterns.
+func NewNeverFocusWorkspaces(patterns []string) (*NeverFocusWorkspaces, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid never-focus pattern '%s': %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return &NeverFocusWorkspaces{patterns: compiled}, nil
+}
+
+// Match reports whether the workspace must never keep the focus.
+func (n *NeverFocusWorkspaces) Match(workspace string) bool {
+	if IsScratchpadWorkspace(workspace) {
+		return true
+	}
+
+	if n == nil {
+		return false
+	}
+	for _, pattern := range n.patterns {
+		if pattern.MatchString(workspace) {
+			return true
+		}
+	}
+
+	return false
+}
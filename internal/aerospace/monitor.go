@@ -0,0 +1,141 @@
+package aerospace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+)
+
+// Monitors accepted by `--monitor`, besides a monitor ID as listed by
+// `aerospace list-monitors`.
+const (
+	MonitorFocused = "focused"
+	MonitorMouse   = "mouse"
+)
+
+// ValidateMonitor makes sure the monitor is focused, mouse or a monitor ID.
+func ValidateMonitor(monitor string) error {
+	if monitor == MonitorFocused || monitor == MonitorMouse {
+		return nil
+	}
+	if id, err := strconv.Atoi(monitor); err == nil && id > 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"invalid monitor '%s': expected %s, %s or a monitor id",
+		monitor,
+		MonitorFocused,
+		MonitorMouse,
+	)
+}
+
+// Monitor is a monitor as listed by `aerospace list-monitors`.
+type Monitor struct {
+	ID   int    `json:"monitor-id"`
+	Name string `json:"monitor-name"`
+}
+
+// GetMonitor returns the monitor with the focus, under the mouse cursor or
+// with the given ID.
+// Equivalent to: `aerospace list-monitors [--focused|--mouse] --json`.
+func (c *AeroSpaceClient) GetMonitor(
+	ctx context.Context,
+	monitor string,
+) (*Monitor, error) {
+	byID := false
+	args := []string{"--json"}
+	switch monitor {
+	case MonitorFocused:
+		args = []string{"--focused", "--json"}
+	case MonitorMouse:
+		args = []string{"--mouse", "--json"}
+	default:
+		byID = true
+	}
+
+	return query(ctx, c, func(cli AeroSpaceWMClient) (*Monitor, error) {
+		response, err := cli.Connection().SendCommand("list-monitors", args)
+		if err != nil {
+			return nil, err
+		}
+		if response.ExitCode != 0 {
+			return nil, errors.New(response.StdErr)
+		}
+
+		var monitors []Monitor
+		if err = json.Unmarshal([]byte(response.StdOut), &monitors); err != nil {
+			return nil, fmt.Errorf("unable to parse monitors: %w", err)
+		}
+		for _, candidate := range monitors {
+			if !byID || strconv.Itoa(candidate.ID) == monitor {
+				return &candidate, nil
+			}
+		}
+
+		return nil, fmt.Errorf("monitor '%s' not found", monitor)
+	})
+}
+
+// GetVisibleWorkspace returns the workspace visible on the monitor.
+// Equivalent to: `aerospace list-workspaces --monitor <monitor> --visible --json`.
+func (c *AeroSpaceClient) GetVisibleWorkspace(
+	ctx context.Context,
+	monitor string,
+) (*workspaces.Workspace, error) {
+	return query(ctx, c, func(cli AeroSpaceWMClient) (*workspaces.Workspace, error) {
+		response, err := cli.Connection().SendCommand(
+			"list-workspaces",
+			[]string{"--monitor", monitor, "--visible", "--json"},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if response.ExitCode != 0 {
+			return nil, errors.New(response.StdErr)
+		}
+
+		var visible []workspaces.Workspace
+		if err = json.Unmarshal([]byte(response.StdOut), &visible); err != nil {
+			return nil, fmt.Errorf("unable to parse workspaces: %w", err)
+		}
+		if len(visible) == 0 {
+			return nil, fmt.Errorf("no workspace visible on monitor '%s'", monitor)
+		}
+
+		return &visible[0], nil
+	})
+}
+
+// IsWorkspaceVisible reports whether the workspace is visible on a monitor.
+// Equivalent to: `aerospace list-workspaces --monitor all --visible --json`.
+func (c *AeroSpaceClient) IsWorkspaceVisible(
+	ctx context.Context,
+	workspace string,
+) (bool, error) {
+	return query(ctx, c, func(cli AeroSpaceWMClient) (bool, error) {
+		response, err := cli.Connection().SendCommand(
+			"list-workspaces",
+			[]string{"--monitor", "all", "--visible", "--json"},
+		)
+		if err != nil {
+			return false, err
+		}
+		if response.ExitCode != 0 {
+			return false, errors.New(response.StdErr)
+		}
+
+		var visible []workspaces.Workspace
+		if err = json.Unmarshal([]byte(response.StdOut), &visible); err != nil {
+			return false, fmt.Errorf("unable to parse workspaces: %w", err)
+		}
+
+		return slices.ContainsFunc(visible, func(candidate workspaces.Workspace) bool {
+			return candidate.Workspace == workspace
+		}), nil
+	})
+}
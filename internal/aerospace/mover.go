@@ -1,79 +1,203 @@
 package aerospace
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/layout"
 	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
 	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
-	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
 )
 
+// ErrAlreadyInWorkspace is returned by the mover when the window already
+// lives in the target workspace, in which case no move is sent to AeroSpace.
+var ErrAlreadyInWorkspace = errors.New("window already belongs to workspace")
+
 type Mover interface {
 	// MoveWindowToScratchpad sends a window to a workspace
-	MoveWindowToScratchpad(window windows.Window) error
+	//
+	// Returns ErrAlreadyInWorkspace if the window is already in the scratchpad
+	MoveWindowToScratchpad(ctx context.Context, window windows.Window) error
 
 	// MoveWindowToWorkspace sends a window to a workspace and set focus
+	//
+	// Returns ErrAlreadyInWorkspace if the window is already in the workspace
 	MoveWindowToWorkspace(
-		window windows.Window,
-		workspace workspaces.Workspace,
+		ctx context.Context,
+		window *windows.Window,
+		workspace *workspaces.Workspace,
 		shouldSetFocus bool,
 	) error
 }
 
 type MoverAeroSpace struct {
-	aerospace AeroSpaceWMClient
+	aerospace  AeroSpaceWMClient
+	scratchpad ScratchpadResolver
+	steps      []MoveStep
+}
+
+// MoveStep is a move completed by the Mover, with the state of the window
+// before the move so it can be reverted.
+type MoveStep struct {
+	// Window is the window as it was before the move
+	Window windows.Window
+	// TargetWorkspace is the workspace the window was moved to
+	TargetWorkspace string
+	// SetFloating reports whether the move also made the window floating
+	SetFloating bool
+}
+
+// RollbackStep is the outcome of reverting a MoveStep.
+type RollbackStep struct {
+	MoveStep
+
+	// Err is set when the window could not be reverted
+	Err error
 }
 
 func NewAeroSpaceMover(aerospace AeroSpaceWMClient) MoverAeroSpace {
 	return MoverAeroSpace{
-		aerospace: aerospace,
+		aerospace:  aerospace,
+		scratchpad: DefaultScratchpad,
 	}
 }
 
-func (a *MoverAeroSpace) MoveWindowToScratchpad(
-	window windows.Window,
-) error {
+// SetScratchpadResolver changes where MoveWindowToScratchpad stashes windows,
+// see PerWorkspaceScratchpad.
+func (a *MoverAeroSpace) SetScratchpadResolver(resolve ScratchpadResolver) {
+	a.scratchpad = resolve
+}
+
+// ScratchpadFor returns the scratchpad workspace the window is stashed in.
+func (a *MoverAeroSpace) ScratchpadFor(window windows.Window) string {
+	if a.scratchpad == nil {
+		return DefaultScratchpad(window)
+	}
+	return a.scratchpad(window)
+}
+
+// Steps returns the moves completed so far, in order.
+func (a *MoverAeroSpace) Steps() []MoveStep {
+	return a.steps
+}
+
+// Rollback reverts the completed moves, most recent first, sending each
+// window back to its previous workspace and restoring its tiling layout.
+// Every step is attempted even when some fail.
+func (a *MoverAeroSpace) Rollback(ctx context.Context) []RollbackStep {
 	logger := logger.GetDefaultLogger()
-	logger.LogDebug("MOVING: MoveWindowToScratchpad", "window", window)
 
-	// Use wrapper's MoveWindowToWorkspace if available (for dry-run support)
-	var err error
-	if wrapper, ok := a.aerospace.(*AeroSpaceClient); ok {
-		err = wrapper.MoveWindowToWorkspace(
-			window.WindowID,
-			constants.DefaultScratchpadWorkspaceName,
+	results := make([]RollbackStep, 0, len(a.steps))
+	for i := len(a.steps) - 1; i >= 0; i-- {
+		step := a.steps[i]
+		err := a.moveWindow(ctx, step.Window.WindowID, step.Window.Workspace)
+		if err == nil && step.SetFloating && wasTiling(step.Window) {
+			err = a.setLayout(ctx, step.Window.WindowID, "tiling")
+		}
+		logger.LogDebug(
+			"MOVER: rolled back move",
+			"window", step.Window,
+			"from-workspace", step.TargetWorkspace,
+			"error", err,
 		)
-	} else {
-		windowID := window.WindowID
-		err = a.aerospace.Workspaces().MoveWindowToWorkspaceWithOpts(
+		results = append(results, RollbackStep{MoveStep: step, Err: err})
+	}
+	a.steps = nil
+
+	return results
+}
+
+// wasTiling reports whether the window had a known tiling layout, e.g.
+// h_tiles or v_accordion.
+func wasTiling(window windows.Window) bool {
+	return window.WindowLayout != "" && window.WindowLayout != "floating"
+}
+
+// moveWindow sends the window to the workspace, through the wrapper when
+// available for dry-run support.
+func (a *MoverAeroSpace) moveWindow(
+	ctx context.Context,
+	windowID int,
+	workspaceName string,
+) error {
+	if wrapper, ok := a.aerospace.(*AeroSpaceClient); ok {
+		return wrapper.MoveWindowToWorkspace(ctx, windowID, workspaceName)
+	}
+
+	return withContextErr(ctx, nil, func() error {
+		return a.aerospace.Workspaces().MoveWindowToWorkspaceWithOpts(
 			workspaces.MoveWindowToWorkspaceArgs{
-				WorkspaceName: constants.DefaultScratchpadWorkspaceName,
+				WorkspaceName: workspaceName,
 			},
 			workspaces.MoveWindowToWorkspaceOpts{
 				WindowID: &windowID,
 			},
 		)
+	})
+}
+
+// setLayout changes the window layout, through the wrapper when available
+// for dry-run support.
+func (a *MoverAeroSpace) setLayout(
+	ctx context.Context,
+	windowID int,
+	layoutName string,
+) error {
+	if wrapper, ok := a.aerospace.(*AeroSpaceClient); ok {
+		return wrapper.SetLayout(ctx, windowID, layoutName)
+	}
+
+	return withContextErr(ctx, nil, func() error {
+		return a.aerospace.Layout().SetLayout([]string{layoutName}, layout.SetLayoutOpts{
+			WindowID: layout.IntPtr(windowID),
+		})
+	})
+}
+
+func (a *MoverAeroSpace) MoveWindowToScratchpad(
+	ctx context.Context,
+	window windows.Window,
+) error {
+	logger := logger.GetDefaultLogger()
+	logger.LogDebug("MOVING: MoveWindowToScratchpad", "window", window)
+
+	scratchpad := a.ScratchpadFor(window)
+	if window.Workspace == scratchpad {
+		logger.LogDebug(
+			"MOVING: window already in scratchpad, skipping",
+			"window", window,
+		)
+		return fmt.Errorf(
+			"%w '%s'",
+			ErrAlreadyInWorkspace,
+			scratchpad,
+		)
 	}
+
+	err := a.moveWindow(ctx, window.WindowID, scratchpad)
 	logger.LogDebug(
 		"MOVING: after MoveWindowToWorkspace",
 		"window", window,
-		"to-workspace", constants.DefaultScratchpadWorkspaceName,
+		"to-workspace", scratchpad,
 		"error", err,
 	)
 	if err != nil {
 		return err
 	}
 
-	// Use wrapper's SetLayout if available (for dry-run support)
-	if wrapper, ok := a.aerospace.(*AeroSpaceClient); ok {
-		err = wrapper.SetLayout(window.WindowID, "floating")
-	} else {
-		err = a.aerospace.Layout().SetLayout([]string{"floating"}, layout.SetLayoutOpts{
-			WindowID: layout.IntPtr(window.WindowID),
-		})
+	step := MoveStep{
+		Window:          window,
+		TargetWorkspace: scratchpad,
+	}
+	// Record the move before the layout change, a floating failure leaves
+	// the window moved anyway
+	a.steps = append(a.steps, step)
+
+	err = a.setLayout(ctx, window.WindowID, "floating")
+	if isContextError(err) {
+		return err
 	}
 	if err != nil {
 		logger.LogDebug(
@@ -81,12 +205,15 @@ func (a *MoverAeroSpace) MoveWindowToScratchpad(
 			"window", window,
 			"error", err,
 		)
+		return nil
 	}
+	a.steps[len(a.steps)-1].SetFloating = true
 
 	return nil
 }
 
 func (a *MoverAeroSpace) MoveWindowToWorkspace(
+	ctx context.Context,
 	window *windows.Window,
 	workspace *workspaces.Workspace,
 	shouldSetFocus bool,
@@ -98,38 +225,26 @@ func (a *MoverAeroSpace) MoveWindowToWorkspace(
 		return errors.New("workspace is nil")
 	}
 
-	// Use wrapper's MoveWindowToWorkspace if available (for dry-run support)
-	if wrapper, ok := a.aerospace.(*AeroSpaceClient); ok {
-		if err := wrapper.MoveWindowToWorkspace(
-			window.WindowID,
+	if window.Workspace == workspace.Workspace {
+		return fmt.Errorf(
+			"%w '%s'",
+			ErrAlreadyInWorkspace,
 			workspace.Workspace,
-		); err != nil {
-			return fmt.Errorf(
-				"unable to move window '%+v' to workspace '%s': %w",
-				window,
-				workspace.Workspace,
-				err,
-			)
-		}
-	} else {
-		// Fallback to direct service call
-		windowID := window.WindowID
-		if err := a.aerospace.Workspaces().MoveWindowToWorkspaceWithOpts(
-			workspaces.MoveWindowToWorkspaceArgs{
-				WorkspaceName: workspace.Workspace,
-			},
-			workspaces.MoveWindowToWorkspaceOpts{
-				WindowID: &windowID,
-			},
-		); err != nil {
-			return fmt.Errorf(
-				"unable to move window '%+v' to workspace '%s': %w",
-				window,
-				workspace.Workspace,
-				err,
-			)
-		}
+		)
+	}
+
+	if err := a.moveWindow(ctx, window.WindowID, workspace.Workspace); err != nil {
+		return fmt.Errorf(
+			"unable to move window '%+v' to workspace '%s': %w",
+			window,
+			workspace.Workspace,
+			err,
+		)
 	}
+	a.steps = append(a.steps, MoveStep{
+		Window:          *window,
+		TargetWorkspace: workspace.Workspace,
+	})
 
 	if !shouldSetFocus {
 		return nil
@@ -137,7 +252,7 @@ func (a *MoverAeroSpace) MoveWindowToWorkspace(
 
 	// Use wrapper's SetFocusByWindowID if available (for dry-run support)
 	if wrapper, ok := a.aerospace.(*AeroSpaceClient); ok {
-		if err := wrapper.SetFocusByWindowID(window.WindowID); err != nil {
+		if err := wrapper.SetFocusByWindowID(ctx, window.WindowID); err != nil {
 			return fmt.Errorf(
 				"unable to set focus to window '%+v': %w",
 				window,
@@ -146,7 +261,9 @@ func (a *MoverAeroSpace) MoveWindowToWorkspace(
 		}
 	} else {
 		// Fallback to direct service call
-		if err := a.aerospace.Focus().SetFocusByWindowID(window.WindowID); err != nil {
+		if err := withContextErr(ctx, nil, func() error {
+			return a.aerospace.Focus().SetFocusByWindowID(window.WindowID)
+		}); err != nil {
 			return fmt.Errorf(
 				"unable to set focus to window '%+v': %w",
 				window,
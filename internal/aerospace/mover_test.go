@@ -0,0 +1,74 @@
+package aerospace_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
+)
+
+func TestMoverRollback(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+	ctx := context.Background()
+
+	t.Run("reverts the completed moves in reverse order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// The plan of a dry-run client shows what rollback sends to AeroSpace
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		client := aerospace.NewAeroSpaceClient(mockClient)
+		client.SetOptions(aerospace.ClientOpts{DryRun: true})
+
+		mover := aerospace.NewAeroSpaceMover(client)
+		tiled := windows.Window{WindowID: 1, Workspace: "ws1", WindowLayout: "h_tiles"}
+		floating := windows.Window{WindowID: 2, Workspace: "ws2", WindowLayout: "floating"}
+		if err := mover.MoveWindowToScratchpad(ctx, tiled); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := mover.MoveWindowToWorkspace(
+			ctx,
+			&floating,
+			&workspaces.Workspace{Workspace: "ws3"},
+			false,
+		); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(mover.Steps()) != 2 {
+			t.Fatalf("expected 2 steps, got %+v", mover.Steps())
+		}
+		client.TakePlan()
+
+		results := mover.Rollback(ctx)
+		if len(results) != 2 ||
+			results[0].Window.WindowID != 2 ||
+			results[1].Window.WindowID != 1 {
+			t.Fatalf("expected rollback of windows 2 then 1, got %+v", results)
+		}
+		for _, result := range results {
+			if result.Err != nil {
+				t.Fatalf("unexpected err: %v", result.Err)
+			}
+		}
+
+		expected := aerospace.Plan{
+			{Action: aerospace.PlanActionToWorkspace, WindowID: 2, Workspace: "ws2"},
+			{Action: aerospace.PlanActionToWorkspace, WindowID: 1, Workspace: "ws1"},
+			{Action: aerospace.PlanActionLayout, WindowID: 1, Detail: "tiling"},
+		}
+		if plan := client.TakePlan(); !reflect.DeepEqual(plan, expected) {
+			t.Fatalf("expected plan %+v, got %+v", expected, plan)
+		}
+
+		if len(mover.Steps()) != 0 {
+			t.Fatalf("expected steps to be cleared, got %+v", mover.Steps())
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package aerospace
+
+// Planned operation actions, named after the output event actions.
+const (
+	PlanActionToWorkspace     = "to-workspace"
+	PlanActionFocus           = "focus"
+	PlanActionFocusNextTiling = "focus-next-tiling"
+	PlanActionLayout          = "layout"
+	PlanActionFullscreen      = "fullscreen"
+	PlanActionCenter          = "center"
+	PlanActionResize          = "resize"
+	PlanActionClose           = "close"
+)
+
+// PlannedOperation is a mutating operation recorded instead of being sent to
+// AeroSpace in dry-run mode.
+type PlannedOperation struct {
+	// Action is one of the PlanAction constants
+	Action string
+	// WindowID is the target window, 0 when the operation has none
+	WindowID int
+	// Workspace is the target workspace of to-workspace operations
+	Workspace string
+	// Detail holds the operation argument, e.g. the layout name
+	Detail string
+}
+
+// Plan lists the operations a dry-run would have executed, in order.
+type Plan []PlannedOperation
+
+// record adds an operation to the plan of a dry-run client.
+func (c *AeroSpaceClient) record(operation PlannedOperation) {
+	c.plan = append(c.plan, operation)
+}
+
+// TakePlan returns the operations recorded since the last call and clears
+// them.
+func (c *AeroSpaceClient) TakePlan() Plan {
+	plan := c.plan
+	c.plan = nil
+	return plan
+}
+
+// IsDryRun reports whether mutating operations are only recorded.
+func (c *AeroSpaceClient) IsDryRun() bool {
+	return c.dryRun
+}
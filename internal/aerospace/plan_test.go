@@ -0,0 +1,67 @@
+package aerospace_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
+)
+
+func TestDryRunPlan(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records mutating operations instead of sending them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// No expectations, any call to AeroSpace fails the test
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		client := aerospace.NewAeroSpaceClient(mockClient)
+		client.SetOptions(aerospace.ClientOpts{DryRun: true})
+
+		mover := aerospace.NewAeroSpaceMover(client)
+		if err := mover.MoveWindowToScratchpad(ctx, windows.Window{WindowID: 1, Workspace: "ws1"}); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := client.MoveWindowToWorkspaceAndFocus(ctx, 2, "ws2"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		extended := aerospace.NewExtendedAeroSpaceClient(client)
+		if err := extended.ApplyGeometry(ctx, 3, "60%x90%@top"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := extended.SetFullscreen(ctx, 3, true); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		expected := aerospace.Plan{
+			{Action: aerospace.PlanActionToWorkspace, WindowID: 1, Workspace: ".scratchpad"},
+			{Action: aerospace.PlanActionLayout, WindowID: 1, Detail: "floating"},
+			{
+				Action:    aerospace.PlanActionToWorkspace,
+				WindowID:  2,
+				Workspace: "ws2",
+				Detail:    "focus-follows-window",
+			},
+			{Action: aerospace.PlanActionLayout, WindowID: 3, Detail: "floating"},
+			{Action: aerospace.PlanActionFocus, WindowID: 3},
+			{Action: aerospace.PlanActionResize, WindowID: 3, Detail: "60%x90%@top"},
+			{Action: aerospace.PlanActionFullscreen, WindowID: 3, Detail: "on"},
+		}
+
+		plan := client.TakePlan()
+		if !reflect.DeepEqual(plan, expected) {
+			t.Fatalf("expected plan %+v, got %+v", expected, plan)
+		}
+
+		if len(client.TakePlan()) != 0 {
+			t.Fatalf("expected the plan to be cleared")
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package aerospace
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
@@ -16,47 +17,62 @@ type Querier interface {
 	// IsWindowInWorkspace checks if a window is in a workspace
 	//
 	// Returns true if the window is in the workspace
-	IsWindowInWorkspace(windowID int, workspaceName string) (bool, error)
+	IsWindowInWorkspace(
+		ctx context.Context,
+		windowID int,
+		workspaceName string,
+	) (bool, error)
 
 	// IsWindowInFocusedWorkspace checks if a window is in the focused workspace
 	//
 	// Returns true if the window is in the focused workspace
-	IsWindowInFocusedWorkspace(windowID int) (bool, error)
+	IsWindowInFocusedWorkspace(ctx context.Context, windowID int) (bool, error)
 
 	// IsWindowFocused checks if a window is focused
 	//
 	// Returns true if the window is focused
-	IsWindowFocused(windowID int) (bool, error)
+	IsWindowFocused(ctx context.Context, windowID int) (bool, error)
 
 	// GetNextScratchpadWindow returns the next scratchpad window in the workspace
-	GetNextScratchpadWindow() (*windows.Window, error)
+	GetNextScratchpadWindow(ctx context.Context) (*windows.Window, error)
+
+	// GetNextScratchpadWindowIn returns the next window of the first given
+	// scratchpad workspace that has any
+	GetNextScratchpadWindowIn(
+		ctx context.Context,
+		scratchpads ...string,
+	) (*windows.Window, error)
 
 	// GetFilteredWindows returns all windows that match the given filters
 	GetFilteredWindows(
+		ctx context.Context,
 		windowNamePattern string,
 		filterFlags []string,
 	) ([]windows.Window, error)
 
 	// GetAllFloatingWindows returns all floating windows
-	GetAllFloatingWindows() ([]windows.Window, error)
+	GetAllFloatingWindows(ctx context.Context) ([]windows.Window, error)
 
 	// GetScratchpadWindows returns all scratchpad windows
 	// A scratchpad window is defined as:
 	// - A window in the .scratchpad workspace, OR
+	// - A window in a per-workspace scratchpad (e.g. .scratchpad-ws-ws1), OR
+	// - A window in a scratchpad pool (e.g. .scratchpad-pool-media), OR
 	// - A floating window (WindowLayout == "floating")
-	GetScratchpadWindows() ([]windows.Window, error)
+	GetScratchpadWindows(ctx context.Context) ([]windows.Window, error)
 }
 
 type QueryMaker struct {
-	cli AeroSpaceWMClient
+	cli *AeroSpaceClient
 }
 
 func (a *QueryMaker) IsWindowInWorkspace(
+	ctx context.Context,
 	windowID int,
 	workspaceName string,
 ) (bool, error) {
 	// Get all windows from the workspace
-	wsWindows, err := a.cli.Windows().GetAllWindowsByWorkspace(workspaceName)
+	wsWindows, err := a.cli.GetAllWindowsByWorkspace(ctx, workspaceName)
 	if err != nil {
 		return false, fmt.Errorf(
 			"unable to get windows from workspace '%s'. Reason: %w",
@@ -76,10 +92,11 @@ func (a *QueryMaker) IsWindowInWorkspace(
 }
 
 func (a *QueryMaker) IsWindowInFocusedWorkspace(
+	ctx context.Context,
 	windowID int,
 ) (bool, error) {
 	// Get the focused workspace
-	focusedWorkspace, err := a.cli.Workspaces().GetFocusedWorkspace()
+	focusedWorkspace, err := a.cli.GetFocusedWorkspace(ctx)
 	if err != nil {
 		return false, fmt.Errorf(
 			"unable to get focused workspace, reason %w",
@@ -88,12 +105,15 @@ func (a *QueryMaker) IsWindowInFocusedWorkspace(
 	}
 
 	// Check if the window is in the focused workspace
-	return a.IsWindowInWorkspace(windowID, focusedWorkspace.Workspace)
+	return a.IsWindowInWorkspace(ctx, windowID, focusedWorkspace.Workspace)
 }
 
-func (a *QueryMaker) IsWindowFocused(windowID int) (bool, error) {
+func (a *QueryMaker) IsWindowFocused(
+	ctx context.Context,
+	windowID int,
+) (bool, error) {
 	// Get the focused window
-	focusedWindow, err := a.cli.Windows().GetFocusedWindow()
+	focusedWindow, err := a.cli.GetFocusedWindow(ctx)
 	if err != nil {
 		return false, fmt.Errorf("unable to get focused window, reason %w", err)
 	}
@@ -102,20 +122,32 @@ func (a *QueryMaker) IsWindowFocused(windowID int) (bool, error) {
 	return focusedWindow.WindowID == windowID, nil
 }
 
-func (a *QueryMaker) GetNextScratchpadWindow() (*windows.Window, error) {
-	// Get all windows from the workspace
-	wsWindows, err := a.cli.Windows().GetAllWindowsByWorkspace(
+func (a *QueryMaker) GetNextScratchpadWindow(
+	ctx context.Context,
+) (*windows.Window, error) {
+	return a.GetNextScratchpadWindowIn(
+		ctx,
 		constants.DefaultScratchpadWorkspaceName,
 	)
-	if err != nil {
-		return nil, err
-	}
+}
 
-	if len(wsWindows) == 0 {
-		return nil, errors.New("no scratchpad windows found")
+func (a *QueryMaker) GetNextScratchpadWindowIn(
+	ctx context.Context,
+	scratchpads ...string,
+) (*windows.Window, error) {
+	for _, scratchpad := range scratchpads {
+		// Get all windows from the workspace
+		wsWindows, err := a.cli.GetAllWindowsByWorkspace(ctx, scratchpad)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(wsWindows) > 0 {
+			return &wsWindows[0], nil
+		}
 	}
 
-	return &wsWindows[0], nil
+	return nil, errors.New("no scratchpad windows found")
 }
 
 // Filter represents a filter with property and regex pattern.
@@ -127,6 +159,7 @@ type Filter struct {
 const filterPartsExpected = 2
 
 func (a *QueryMaker) GetFilteredWindows(
+	ctx context.Context,
 	appNamePattern string,
 	filterFlags []string,
 ) ([]windows.Window, error) {
@@ -155,7 +188,7 @@ func (a *QueryMaker) GetFilteredWindows(
 		return nil, err
 	}
 
-	allWindows, err := a.cli.Windows().GetAllWindows()
+	allWindows, err := a.cli.GetAllWindows(ctx)
 	if err != nil {
 		logger.LogError("FILTER: unable to get all windows", "error", err)
 		return nil, fmt.Errorf("unable to get windows: %w", err)
@@ -204,10 +237,12 @@ func (a *QueryMaker) GetFilteredWindows(
 	return filteredWindows, nil
 }
 
-func (a *QueryMaker) GetAllFloatingWindows() ([]windows.Window, error) {
+func (a *QueryMaker) GetAllFloatingWindows(
+	ctx context.Context,
+) ([]windows.Window, error) {
 	logger := logger.GetDefaultLogger()
 
-	allWindows, err := a.cli.Windows().GetAllWindows()
+	allWindows, err := a.cli.GetAllWindows(ctx)
 	if err != nil {
 		logger.LogError("FILTER: unable to get all windows", "error", err)
 		return nil, fmt.Errorf("unable to get windows: %w", err)
@@ -228,19 +263,25 @@ func (a *QueryMaker) GetAllFloatingWindows() ([]windows.Window, error) {
 	return floatingWindows, nil
 }
 
-func (a *QueryMaker) GetScratchpadWindows() ([]windows.Window, error) {
+func (a *QueryMaker) GetScratchpadWindows(
+	ctx context.Context,
+) ([]windows.Window, error) {
 	logger := logger.GetDefaultLogger()
 
-	allWindows, err := a.cli.Windows().GetAllWindows()
+	allWindows, err := a.cli.GetAllWindows(ctx)
 	if err != nil {
 		logger.LogError("FILTER: unable to get all windows", "error", err)
 		return nil, fmt.Errorf("unable to get windows: %w", err)
 	}
 
 	// Get windows from scratchpad workspace
-	scratchpadWorkspaceWindows, err := a.cli.Windows().GetAllWindowsByWorkspace(
+	scratchpadWorkspaceWindows, err := a.cli.GetAllWindowsByWorkspace(
+		ctx,
 		constants.DefaultScratchpadWorkspaceName,
 	)
+	if isContextError(err) {
+		return nil, err
+	}
 	if err != nil {
 		logger.LogError(
 			"FILTER: unable to get windows from scratchpad workspace",
@@ -258,9 +299,10 @@ func (a *QueryMaker) GetScratchpadWindows() ([]windows.Window, error) {
 		scratchpadWindowMap[window.WindowID] = window
 	}
 
-	// Add floating windows
+	// Add floating windows and the ones stashed in per-workspace scratchpads
 	for _, window := range allWindows {
-		if window.WindowLayout == "floating" {
+		if window.WindowLayout == "floating" ||
+			IsScratchpadWorkspace(window.Workspace) {
 			// Only add if not already in map (avoid duplicates)
 			if _, exists := scratchpadWindowMap[window.WindowID]; !exists {
 				scratchpadWindowMap[window.WindowID] = window
@@ -372,8 +414,16 @@ func ApplyFilters(window windows.Window, filters []Filter) (bool, error) {
 }
 
 // NewAerospaceQuerier creates a new AerospaceQuerier.
+//
+// Given an *AeroSpaceClient the querier shares its connection, so it also
+// benefits from its reconnection.
 func NewAerospaceQuerier(cli AeroSpaceWMClient) Querier {
+	wrapper, ok := cli.(*AeroSpaceClient)
+	if !ok {
+		wrapper = NewAeroSpaceClient(cli)
+	}
+
 	return &QueryMaker{
-		cli: cli,
+		cli: wrapper,
 	}
 }
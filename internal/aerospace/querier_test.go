@@ -1,8 +1,10 @@
 package aerospace_test
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"go.uber.org/mock/gomock"
 
@@ -21,6 +23,8 @@ func init() {
 
 //nolint:gocyclo,gocognit // Test function aggregates multiple test scenarios for readability
 func TestAeroSpaceQuerier(t *testing.T) {
+	ctx := context.Background()
+
 	t.Run("IsWindowInWorkspace true", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -35,7 +39,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 			Times(1)
 
 		q := aerospace.NewAerospaceQuerier(mockClient)
-		in, err := q.IsWindowInWorkspace(2, workspace)
+		in, err := q.IsWindowInWorkspace(ctx, 2, workspace)
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -57,7 +61,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 			Return(windowsList, nil).
 			Times(1)
 		q := aerospace.NewAerospaceQuerier(mockClient)
-		in, err := q.IsWindowInWorkspace(3, workspace)
+		in, err := q.IsWindowInWorkspace(ctx, 3, workspace)
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -86,7 +90,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 		)
 
 		q := aerospace.NewAerospaceQuerier(mockClient)
-		in, err := q.IsWindowInFocusedWorkspace(5)
+		in, err := q.IsWindowInFocusedWorkspace(ctx, 5)
 		if err != nil || !in {
 			t.Fatalf("expected true, got %v err=%v", in, err)
 		}
@@ -103,7 +107,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 			Return(focused, nil).
 			Times(1)
 		q := aerospace.NewAerospaceQuerier(mockClient)
-		is, err := q.IsWindowFocused(10)
+		is, err := q.IsWindowFocused(ctx, 10)
 		if err != nil || !is {
 			t.Fatalf("expected true, got %v err=%v", is, err)
 		}
@@ -120,7 +124,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 			Return(focused, nil).
 			Times(1)
 		q := aerospace.NewAerospaceQuerier(mockClient)
-		is, err := q.IsWindowFocused(11)
+		is, err := q.IsWindowFocused(ctx, 11)
 		if err != nil || is {
 			t.Fatalf("expected false, got %v err=%v", is, err)
 		}
@@ -137,7 +141,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 			Return(spWin, nil).
 			Times(1)
 		q := aerospace.NewAerospaceQuerier(mockClient)
-		w, err := q.GetNextScratchpadWindow()
+		w, err := q.GetNextScratchpadWindow(ctx)
 		if err != nil || w == nil || w.WindowID != 77 {
 			t.Fatalf("expected 77, got %v err=%v", w, err)
 		}
@@ -155,7 +159,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 				Return([]windows.Window{}, nil).
 				Times(1)
 			q := aerospace.NewAerospaceQuerier(mockClient)
-			if _, err := q.GetNextScratchpadWindow(); err == nil {
+			if _, err := q.GetNextScratchpadWindow(ctx); err == nil {
 				t.Fatalf("expected error when no scratchpad windows")
 			}
 		},
@@ -186,7 +190,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 		)
 
 		q := aerospace.NewAerospaceQuerier(mockClient)
-		wins, err := q.GetScratchpadWindows()
+		wins, err := q.GetScratchpadWindows(ctx)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -217,7 +221,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 		)
 
 		q := aerospace.NewAerospaceQuerier(mockClient)
-		wins, err := q.GetScratchpadWindows()
+		wins, err := q.GetScratchpadWindows(ctx)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -250,7 +254,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 		)
 
 		q := aerospace.NewAerospaceQuerier(mockClient)
-		wins, err := q.GetScratchpadWindows()
+		wins, err := q.GetScratchpadWindows(ctx)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -296,7 +300,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 				Return(all, nil).
 				Times(1)
 			q := aerospace.NewAerospaceQuerier(mockClient)
-			wins, err := q.GetFilteredWindows("Finder", nil)
+			wins, err := q.GetFilteredWindows(ctx, "Finder", nil)
 			if err != nil || len(wins) != 2 {
 				t.Fatalf(
 					"expected 2 finder windows, got %d err=%v",
@@ -343,6 +347,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 			Times(1)
 		q := aerospace.NewAerospaceQuerier(mockClient)
 		wins, err := q.GetFilteredWindows(
+			ctx,
 			"Finder",
 			[]string{"window-title=foo", "app-bundle-id=apple"},
 		)
@@ -357,7 +362,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 
 		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
 		q := aerospace.NewAerospaceQuerier(mockClient)
-		if _, err := q.GetFilteredWindows("[invalid", nil); err == nil {
+		if _, err := q.GetFilteredWindows(ctx, "[invalid", nil); err == nil {
 			t.Fatalf("expected invalid pattern error")
 		}
 	})
@@ -377,7 +382,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 				Return(all, nil).
 				Times(1)
 			q := aerospace.NewAerospaceQuerier(mockClient)
-			if _, err := q.GetFilteredWindows("Finder", []string{"unknown=foo"}); err == nil {
+			if _, err := q.GetFilteredWindows(ctx, "Finder", []string{"unknown=foo"}); err == nil {
 				t.Fatalf("expected unknown property error")
 			}
 		},
@@ -408,7 +413,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 				Return(all, nil).
 				Times(1)
 			q := aerospace.NewAerospaceQuerier(mockClient)
-			if _, err := q.GetFilteredWindows("Finder", nil); err == nil {
+			if _, err := q.GetFilteredWindows(ctx, "Finder", nil); err == nil {
 				t.Fatalf("expected no match error")
 			}
 		},
@@ -426,7 +431,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 				Return(nil, errors.New("mocked_error")).
 				Times(1)
 			q := aerospace.NewAerospaceQuerier(mockClient)
-			if _, err := q.GetFilteredWindows("Finder", nil); err == nil {
+			if _, err := q.GetFilteredWindows(ctx, "Finder", nil); err == nil {
 				t.Fatalf("expected get windows error")
 			}
 		},
@@ -464,6 +469,7 @@ func TestAeroSpaceQuerier(t *testing.T) {
 				Times(1)
 			q := aerospace.NewAerospaceQuerier(mockClient)
 			wins, err := q.GetFilteredWindows(
+				ctx,
 				"Terminal",
 				[]string{"window-id=1"},
 			)
@@ -472,4 +478,30 @@ func TestAeroSpaceQuerier(t *testing.T) {
 			}
 		},
 	)
+
+	t.Run("GetFilteredWindows returns ErrTimeout when AeroSpace hangs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		release := make(chan struct{})
+		defer close(release)
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			DoAndReturn(func() ([]windows.Window, error) {
+				<-release
+				return nil, nil
+			}).
+			Times(1)
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		q := aerospace.NewAerospaceQuerier(mockClient)
+		_, err := q.GetFilteredWindows(timeoutCtx, "Finder", nil)
+		if !errors.Is(err, aerospace.ErrTimeout) {
+			t.Fatalf("expected ErrTimeout, got %v", err)
+		}
+	})
 }
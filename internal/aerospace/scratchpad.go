@@ -0,0 +1,107 @@
+package aerospace
+
+import (
+	"strings"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+)
+
+// Prefixes of the scoped scratchpad workspaces, after the shared scratchpad
+// name: `.scratchpad-pool-media` for the pool `media` and `.scratchpad-ws-1`
+// for the per-workspace scratchpad of workspace `1`. Each kind has its own,
+// so a pool never shares its workspace with a per-workspace scratchpad.
+const (
+	poolScratchpadPrefix      = constants.DefaultScratchpadWorkspaceName + "-pool-"
+	workspaceScratchpadPrefix = constants.DefaultScratchpadWorkspaceName + "-ws-"
+)
+
+// ScratchpadKind tells the scratchpad workspaces apart.
+type ScratchpadKind int
+
+const (
+	// ScratchpadKindNone is any workspace that is not a scratchpad
+	ScratchpadKindNone ScratchpadKind = iota
+	// ScratchpadKindShared is the shared `.scratchpad`
+	ScratchpadKindShared
+	// ScratchpadKindPool is the scratchpad of a named pool
+	ScratchpadKindPool
+	// ScratchpadKindWorkspace is the scratchpad owned by a workspace
+	ScratchpadKindWorkspace
+)
+
+// ScratchpadResolver returns the scratchpad workspace a window is stashed in.
+type ScratchpadResolver func(window windows.Window) string
+
+// DefaultScratchpad stashes every window in the shared scratchpad workspace.
+// Windows already stashed stay where they are.
+func DefaultScratchpad(window windows.Window) string {
+	if IsScratchpadWorkspace(window.Workspace) {
+		return window.Workspace
+	}
+	return constants.DefaultScratchpadWorkspaceName
+}
+
+// PoolScratchpad stashes every window in the workspace of the named pool,
+// moving them from other pools if needed.
+func PoolScratchpad(pool string) ScratchpadResolver {
+	return func(_ windows.Window) string {
+		return PoolWorkspace(pool)
+	}
+}
+
+// PerWorkspaceScratchpad stashes windows in the scratchpad of the workspace
+// they come from. Windows already stashed stay where they are.
+func PerWorkspaceScratchpad(window windows.Window) string {
+	if IsScratchpadWorkspace(window.Workspace) {
+		return window.Workspace
+	}
+	return WorkspaceScratchpad(window.Workspace)
+}
+
+// PoolWorkspace returns the scratchpad workspace of a pool, e.g.
+// `.scratchpad-pool-media` for `media`, or the shared scratchpad when the
+// pool is empty.
+func PoolWorkspace(pool string) string {
+	if pool == "" {
+		return constants.DefaultScratchpadWorkspaceName
+	}
+	return poolScratchpadPrefix + pool
+}
+
+// WorkspaceScratchpad returns the scratchpad owned by a workspace, e.g.
+// `.scratchpad-ws-1` for `1`.
+func WorkspaceScratchpad(workspace string) string {
+	return workspaceScratchpadPrefix + workspace
+}
+
+// IsScratchpadWorkspace reports whether the workspace is the shared
+// scratchpad or a scoped one.
+func IsScratchpadWorkspace(workspace string) bool {
+	kind, _ := ScratchpadScope(workspace)
+	return kind != ScratchpadKindNone
+}
+
+// ScratchpadScope returns the kind of a scratchpad workspace and its scope:
+// the pool name or the owning workspace, empty for the shared scratchpad.
+func ScratchpadScope(workspace string) (ScratchpadKind, string) {
+	if workspace == constants.DefaultScratchpadWorkspaceName {
+		return ScratchpadKindShared, ""
+	}
+	if pool, ok := strings.CutPrefix(workspace, poolScratchpadPrefix); ok && pool != "" {
+		return ScratchpadKindPool, pool
+	}
+	if owner, ok := strings.CutPrefix(workspace, workspaceScratchpadPrefix); ok && owner != "" {
+		return ScratchpadKindWorkspace, owner
+	}
+	return ScratchpadKindNone, ""
+}
+
+// PoolOf returns the pool a scratchpad workspace belongs to, empty when it is
+// not the workspace of a pool.
+func PoolOf(workspace string) string {
+	if kind, pool := ScratchpadScope(workspace); kind == ScratchpadKindPool {
+		return pool
+	}
+	return ""
+}
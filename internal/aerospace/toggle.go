@@ -0,0 +1,284 @@
+package aerospace
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+)
+
+// ToggleAction is what toggling the matched windows does.
+type ToggleAction string
+
+const (
+	// ToggleNone does nothing, no window matched
+	ToggleNone ToggleAction = "none"
+	// ToggleBring brings the windows outside the focused workspace and
+	// focuses the ones already in it
+	ToggleBring ToggleAction = "bring"
+	// ToggleHide stashes the windows, they are all in the focused workspace
+	// and one of them is focused
+	ToggleHide ToggleAction = "hide"
+	// ToggleFocus focuses the windows, they are all in the focused workspace
+	// but none is focused
+	ToggleFocus ToggleAction = "focus"
+)
+
+// ToggleState is what the toggle decision depends on.
+type ToggleState struct {
+	// Outside are the matched windows outside the focused workspace
+	Outside []windows.Window
+	// InFocusedWorkspace are the matched windows in the focused workspace
+	InFocusedWorkspace []windows.Window
+	// AnyFocused reports whether one of InFocusedWorkspace is focused
+	AnyFocused bool
+}
+
+// Add sorts a matched window in the state.
+func (s *ToggleState) Add(window windows.Window, focusedWorkspace string, focused bool) {
+	if window.Workspace != focusedWorkspace {
+		s.Outside = append(s.Outside, window)
+		return
+	}
+
+	s.InFocusedWorkspace = append(s.InFocusedWorkspace, window)
+	// Once a window is focused it stays true
+	s.AnyFocused = s.AnyFocused || focused
+}
+
+// Decide returns the action of the toggle. Bringing windows wins over hiding
+// them, so toggling never ping pongs windows between workspaces.
+func (s ToggleState) Decide() ToggleAction {
+	switch {
+	case len(s.Outside) > 0:
+		return ToggleBring
+	case len(s.InFocusedWorkspace) == 0:
+		return ToggleNone
+	case s.AnyFocused:
+		return ToggleHide
+	default:
+		return ToggleFocus
+	}
+}
+
+// Snapshot is the state of AeroSpace a toggle is planned against.
+type Snapshot struct {
+	// FocusedWorkspace is the workspace the user is on
+	FocusedWorkspace string
+	// FocusedWindowID is the focused window, 0 when none is
+	FocusedWindowID int
+	// PreviousFocus are the windows focused before the matched windows were
+	// shown, by shown window
+	PreviousFocus map[int]int
+}
+
+// Toggle policies, the first value of each is the default.
+const (
+	// BringAll brings every matched window outside the focused workspace
+	BringAll = "all"
+	// BringOne brings only the first one
+	BringOne = "one"
+
+	// HideAllMatching hides every matched window of the focused workspace
+	HideAllMatching = "all-matching"
+	// HideFocusedOnly hides only the focused one
+	HideFocusedOnly = "focused-only"
+
+	// FocusLast focuses the matched windows in order, the last one ends up
+	// focused
+	FocusLast = "last"
+	// FocusFirst focuses only the first matched window
+	FocusFirst = "first"
+	// FocusNone leaves the focus alone
+	FocusNone = "none"
+
+	// AfterHidePrevious focuses the window focused before the windows were
+	// shown, or the next tiling window when unknown
+	AfterHidePrevious = "previous"
+	// AfterHideDFSNext focuses the next tiling window once the windows are
+	// hidden
+	AfterHideDFSNext = "dfs-next"
+	// AfterHideNone leaves the focus to AeroSpace
+	AfterHideNone = "none"
+)
+
+// TogglePolicy selects how a toggle brings, focuses and hides the matched
+// windows. Empty fields use the default policies.
+type TogglePolicy struct {
+	// Bring is BringAll or BringOne
+	Bring string
+	// Hide is HideAllMatching or HideFocusedOnly
+	Hide string
+	// Focus is FocusLast, FocusFirst or FocusNone
+	Focus string
+	// AfterHide is AfterHidePrevious, AfterHideDFSNext or AfterHideNone
+	AfterHide string
+}
+
+// Validate checks every policy is a known one.
+func (p TogglePolicy) Validate() error {
+	for _, policy := range []struct {
+		name    string
+		value   string
+		allowed []string
+	}{
+		{"bring", p.Bring, []string{BringAll, BringOne}},
+		{"hide policy", p.Hide, []string{HideAllMatching, HideFocusedOnly}},
+		{"focus", p.Focus, []string{FocusLast, FocusFirst, FocusNone}},
+		{"after hide", p.AfterHide, []string{AfterHidePrevious, AfterHideDFSNext, AfterHideNone}},
+	} {
+		if policy.value != "" && !slices.Contains(policy.allowed, policy.value) {
+			return fmt.Errorf(
+				"invalid %s %q, must be one of: %s",
+				policy.name,
+				policy.value,
+				strings.Join(policy.allowed, ", "),
+			)
+		}
+	}
+	return nil
+}
+
+// StepAction is what a step of a toggle plan does.
+type StepAction string
+
+const (
+	// StepBring moves the window to the focused workspace
+	StepBring StepAction = "bring"
+	// StepFocus focuses the window
+	StepFocus StepAction = "focus"
+	// StepHide stashes the window in its scratchpad
+	StepHide StepAction = "hide"
+	// StepRefocusTiling moves the focus to the next tiling window, so the
+	// focus does not follow the hidden windows
+	StepRefocusTiling StepAction = "refocus-tiling"
+	// StepRefocusPrevious focuses the window focused before the hidden
+	// windows were shown
+	StepRefocusPrevious StepAction = "refocus-previous"
+)
+
+// ToggleStep is a single action of a toggle plan.
+type ToggleStep struct {
+	Action StepAction
+	// Window is the target window, empty for StepRefocusTiling. Only the ID
+	// and the workspace it is expected in are set for StepRefocusPrevious.
+	Window windows.Window
+	// SetFocus focuses the window once brought, StepBring only
+	SetFocus bool
+}
+
+// PlanToggle returns the decision of toggling the matched windows and the
+// steps executing it with the policy, in order. It does not talk to
+// AeroSpace.
+func PlanToggle(
+	snapshot Snapshot,
+	matched []windows.Window,
+	policy TogglePolicy,
+) (ToggleAction, []ToggleStep) {
+	var state ToggleState
+	for _, window := range matched {
+		state.Add(
+			window,
+			snapshot.FocusedWorkspace,
+			snapshot.FocusedWindowID != 0 && window.WindowID == snapshot.FocusedWindowID,
+		)
+	}
+
+	action := state.Decide()
+	var steps []ToggleStep
+	switch action {
+	case ToggleNone:
+	case ToggleBring:
+		brought := state.Outside
+		if policy.Bring == BringOne {
+			brought = brought[:1]
+		}
+		for _, window := range brought {
+			steps = append(steps, ToggleStep{
+				Action: StepBring,
+				Window: window,
+				// Keep the focus on the matched window already focused
+				SetFocus: (policy.Focus == "" || policy.Focus == FocusLast) && !state.AnyFocused,
+			})
+		}
+
+		if policy.Focus == FocusFirst {
+			// A focused match already holds the focus, do not steal it
+			if !state.AnyFocused {
+				steps = append(steps, focusSteps(brought[:1], policy)...)
+			}
+			break
+		}
+		// Make sure to bring the remaining matched windows to the front
+		steps = append(steps, focusSteps(state.InFocusedWorkspace, policy)...)
+	case ToggleHide:
+		hidden := state.InFocusedWorkspace
+		if policy.Hide == HideFocusedOnly {
+			hidden = slices.DeleteFunc(slices.Clone(hidden), func(window windows.Window) bool {
+				return window.WindowID != snapshot.FocusedWindowID
+			})
+		}
+
+		steps = append(steps, refocusStep(snapshot, hidden, policy)...)
+		for _, window := range hidden {
+			steps = append(steps, ToggleStep{Action: StepHide, Window: window})
+		}
+	case ToggleFocus:
+		steps = focusSteps(state.InFocusedWorkspace, policy)
+	}
+
+	return action, steps
+}
+
+// focusSteps focuses the windows following the focus policy.
+func focusSteps(matched []windows.Window, policy TogglePolicy) []ToggleStep {
+	switch policy.Focus {
+	case FocusNone:
+		return nil
+	case FocusFirst:
+		matched = matched[:min(len(matched), 1)]
+	}
+
+	steps := make([]ToggleStep, 0, len(matched))
+	for _, window := range matched {
+		steps = append(steps, ToggleStep{Action: StepFocus, Window: window})
+	}
+	return steps
+}
+
+// refocusStep moves the focus away from the windows about to be hidden,
+// following the after hide policy.
+func refocusStep(
+	snapshot Snapshot,
+	hidden []windows.Window,
+	policy TogglePolicy,
+) []ToggleStep {
+	switch policy.AfterHide {
+	case AfterHideNone:
+		return nil
+	case AfterHideDFSNext:
+		return []ToggleStep{{Action: StepRefocusTiling}}
+	}
+
+	if previous, ok := PreviousFocus(snapshot.PreviousFocus, hidden); ok {
+		return []ToggleStep{{Action: StepRefocusPrevious, Window: previous}}
+	}
+	return []ToggleStep{{Action: StepRefocusTiling}}
+}
+
+// PreviousFocus returns the window focused before the first of the hidden
+// windows was shown, with the workspace it is expected in. Windows about to
+// be hidden themselves are skipped.
+func PreviousFocus(previousFocus map[int]int, hidden []windows.Window) (windows.Window, bool) {
+	for _, window := range hidden {
+		previous := previousFocus[window.WindowID]
+		if previous == 0 || slices.ContainsFunc(hidden, func(hiddenWindow windows.Window) bool {
+			return hiddenWindow.WindowID == previous
+		}) {
+			continue
+		}
+		return windows.Window{WindowID: previous, Workspace: window.Workspace}, true
+	}
+	return windows.Window{}, false
+}
@@ -0,0 +1,266 @@
+package aerospace_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+)
+
+func TestToggleState(t *testing.T) {
+	stashed := windows.Window{WindowID: 1, Workspace: ".scratchpad"}
+	visible := windows.Window{WindowID: 2, Workspace: "ws1"}
+	other := windows.Window{WindowID: 3, Workspace: "ws1"}
+
+	type window struct {
+		window  windows.Window
+		focused bool
+	}
+
+	for _, tc := range []struct {
+		name     string
+		windows  []window
+		expected aerospace.ToggleAction
+	}{
+		{"no windows", nil, aerospace.ToggleNone},
+		{"stashed window", []window{{stashed, false}}, aerospace.ToggleBring},
+		{"visible and focused", []window{{visible, true}}, aerospace.ToggleHide},
+		{"visible but not focused", []window{{visible, false}}, aerospace.ToggleFocus},
+		{
+			"one of the visible ones focused",
+			[]window{{visible, false}, {other, true}},
+			aerospace.ToggleHide,
+		},
+		{
+			"bringing wins over hiding",
+			[]window{{visible, true}, {stashed, false}},
+			aerospace.ToggleBring,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var state aerospace.ToggleState
+			for _, w := range tc.windows {
+				state.Add(w.window, "ws1", w.focused)
+			}
+
+			if action := state.Decide(); action != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, action)
+			}
+		})
+	}
+
+	t.Run("sorts the windows by workspace", func(t *testing.T) {
+		var state aerospace.ToggleState
+		state.Add(stashed, "ws1", false)
+		state.Add(visible, "ws1", true)
+		state.Add(other, "ws1", false)
+
+		if len(state.Outside) != 1 || state.Outside[0] != stashed {
+			t.Errorf("expected %+v outside, got %+v", stashed, state.Outside)
+		}
+		if len(state.InFocusedWorkspace) != 2 || !state.AnyFocused {
+			t.Errorf("expected the 2 focused workspace windows, one focused, got %+v", state)
+		}
+	})
+}
+
+func TestPlanToggle(t *testing.T) {
+	stashed := windows.Window{WindowID: 1, Workspace: ".scratchpad"}
+	elsewhere := windows.Window{WindowID: 2, Workspace: "ws2"}
+	visible := windows.Window{WindowID: 3, Workspace: "ws1"}
+	other := windows.Window{WindowID: 4, Workspace: "ws1"}
+
+	bring := func(window windows.Window, setFocus bool) aerospace.ToggleStep {
+		return aerospace.ToggleStep{Action: aerospace.StepBring, Window: window, SetFocus: setFocus}
+	}
+	focus := func(window windows.Window) aerospace.ToggleStep {
+		return aerospace.ToggleStep{Action: aerospace.StepFocus, Window: window}
+	}
+	hide := func(window windows.Window) aerospace.ToggleStep {
+		return aerospace.ToggleStep{Action: aerospace.StepHide, Window: window}
+	}
+	refocus := aerospace.ToggleStep{Action: aerospace.StepRefocusTiling}
+
+	for _, tc := range []struct {
+		name           string
+		focusedWindow  int
+		previous       map[int]int
+		policy         aerospace.TogglePolicy
+		matched        []windows.Window
+		expectedAction aerospace.ToggleAction
+		expectedSteps  []aerospace.ToggleStep
+	}{
+		{
+			name:           "nothing matched",
+			expectedAction: aerospace.ToggleNone,
+		},
+		{
+			name:           "brings and focuses stashed windows",
+			matched:        []windows.Window{stashed, elsewhere},
+			expectedAction: aerospace.ToggleBring,
+			expectedSteps:  []aerospace.ToggleStep{bring(stashed, true), bring(elsewhere, true)},
+		},
+		{
+			name:           "brings without stealing the focus of a focused match",
+			focusedWindow:  visible.WindowID,
+			matched:        []windows.Window{visible, stashed},
+			expectedAction: aerospace.ToggleBring,
+			expectedSteps:  []aerospace.ToggleStep{bring(stashed, false), focus(visible)},
+		},
+		{
+			name:           "brings and then focuses the visible matches",
+			focusedWindow:  99,
+			matched:        []windows.Window{visible, stashed, other},
+			expectedAction: aerospace.ToggleBring,
+			expectedSteps: []aerospace.ToggleStep{
+				bring(stashed, true), focus(visible), focus(other),
+			},
+		},
+		{
+			name:           "hides the visible matches when one is focused",
+			focusedWindow:  other.WindowID,
+			matched:        []windows.Window{visible, other},
+			expectedAction: aerospace.ToggleHide,
+			expectedSteps:  []aerospace.ToggleStep{refocus, hide(visible), hide(other)},
+		},
+		{
+			name:           "focuses the visible matches when none is focused",
+			matched:        []windows.Window{visible, other},
+			expectedAction: aerospace.ToggleFocus,
+			expectedSteps:  []aerospace.ToggleStep{focus(visible), focus(other)},
+		},
+		{
+			name:           "brings only one window",
+			policy:         aerospace.TogglePolicy{Bring: aerospace.BringOne},
+			matched:        []windows.Window{stashed, elsewhere},
+			expectedAction: aerospace.ToggleBring,
+			expectedSteps:  []aerospace.ToggleStep{bring(stashed, true)},
+		},
+		{
+			name:           "brings and focuses the first window",
+			policy:         aerospace.TogglePolicy{Focus: aerospace.FocusFirst},
+			matched:        []windows.Window{stashed, elsewhere, visible},
+			expectedAction: aerospace.ToggleBring,
+			expectedSteps: []aerospace.ToggleStep{
+				bring(stashed, false), bring(elsewhere, false), focus(stashed),
+			},
+		},
+		{
+			name:           "brings the first window without stealing the focus of a focused match",
+			policy:         aerospace.TogglePolicy{Focus: aerospace.FocusFirst},
+			focusedWindow:  visible.WindowID,
+			matched:        []windows.Window{visible, stashed, elsewhere},
+			expectedAction: aerospace.ToggleBring,
+			expectedSteps:  []aerospace.ToggleStep{bring(stashed, false), bring(elsewhere, false)},
+		},
+		{
+			name:           "brings without focusing",
+			policy:         aerospace.TogglePolicy{Focus: aerospace.FocusNone},
+			matched:        []windows.Window{stashed, visible},
+			expectedAction: aerospace.ToggleBring,
+			expectedSteps:  []aerospace.ToggleStep{bring(stashed, false)},
+		},
+		{
+			name:           "focuses the first visible window",
+			policy:         aerospace.TogglePolicy{Focus: aerospace.FocusFirst},
+			matched:        []windows.Window{visible, other},
+			expectedAction: aerospace.ToggleFocus,
+			expectedSteps:  []aerospace.ToggleStep{focus(visible)},
+		},
+		{
+			name:           "hides only the focused window",
+			policy:         aerospace.TogglePolicy{Hide: aerospace.HideFocusedOnly},
+			focusedWindow:  other.WindowID,
+			matched:        []windows.Window{visible, other},
+			expectedAction: aerospace.ToggleHide,
+			expectedSteps:  []aerospace.ToggleStep{refocus, hide(other)},
+		},
+		{
+			name:           "hides without moving the focus",
+			policy:         aerospace.TogglePolicy{AfterHide: aerospace.AfterHideNone},
+			focusedWindow:  visible.WindowID,
+			matched:        []windows.Window{visible},
+			expectedAction: aerospace.ToggleHide,
+			expectedSteps:  []aerospace.ToggleStep{hide(visible)},
+		},
+		{
+			name:           "hides and focuses the previous window",
+			policy:         aerospace.TogglePolicy{AfterHide: aerospace.AfterHidePrevious},
+			focusedWindow:  visible.WindowID,
+			previous:       map[int]int{visible.WindowID: 42},
+			matched:        []windows.Window{visible},
+			expectedAction: aerospace.ToggleHide,
+			expectedSteps: []aerospace.ToggleStep{
+				{Action: aerospace.StepRefocusPrevious, Window: windows.Window{WindowID: 42, Workspace: "ws1"}},
+				hide(visible),
+			},
+		},
+		{
+			name:           "falls back to the next tiling window when the previous one is hidden",
+			policy:         aerospace.TogglePolicy{AfterHide: aerospace.AfterHidePrevious},
+			focusedWindow:  visible.WindowID,
+			previous:       map[int]int{visible.WindowID: other.WindowID},
+			matched:        []windows.Window{visible, other},
+			expectedAction: aerospace.ToggleHide,
+			expectedSteps:  []aerospace.ToggleStep{refocus, hide(visible), hide(other)},
+		},
+		{
+			name:           "focuses the next tiling window regardless of the previous one",
+			policy:         aerospace.TogglePolicy{AfterHide: aerospace.AfterHideDFSNext},
+			focusedWindow:  visible.WindowID,
+			previous:       map[int]int{visible.WindowID: 42},
+			matched:        []windows.Window{visible},
+			expectedAction: aerospace.ToggleHide,
+			expectedSteps:  []aerospace.ToggleStep{refocus, hide(visible)},
+		},
+		{
+			name:           "falls back to the next tiling window without previous window",
+			policy:         aerospace.TogglePolicy{AfterHide: aerospace.AfterHidePrevious},
+			focusedWindow:  visible.WindowID,
+			matched:        []windows.Window{visible},
+			expectedAction: aerospace.ToggleHide,
+			expectedSteps:  []aerospace.ToggleStep{refocus, hide(visible)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			action, steps := aerospace.PlanToggle(aerospace.Snapshot{
+				FocusedWorkspace: "ws1",
+				FocusedWindowID:  tc.focusedWindow,
+				PreviousFocus:    tc.previous,
+			}, tc.matched, tc.policy)
+
+			if action != tc.expectedAction {
+				t.Errorf("expected action %q, got %q", tc.expectedAction, action)
+			}
+			if !reflect.DeepEqual(steps, tc.expectedSteps) && len(steps)+len(tc.expectedSteps) > 0 {
+				t.Errorf("expected steps %+v, got %+v", tc.expectedSteps, steps)
+			}
+		})
+	}
+}
+
+func TestTogglePolicyValidate(t *testing.T) {
+	valid := []aerospace.TogglePolicy{
+		{},
+		{Bring: aerospace.BringOne, Hide: aerospace.HideFocusedOnly},
+		{Focus: aerospace.FocusNone, AfterHide: aerospace.AfterHidePrevious},
+	}
+	for _, policy := range valid {
+		if err := policy.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", policy, err)
+		}
+	}
+
+	invalid := []aerospace.TogglePolicy{
+		{Bring: "some"},
+		{Hide: "focused"},
+		{Focus: "middle"},
+		{AfterHide: "dfs-prev"},
+	}
+	for _, policy := range invalid {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", policy)
+		}
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"reflect"
 	"strconv"
 	"strings"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
 )
 
 type OutputFormat string
@@ -19,16 +22,8 @@ const (
 )
 
 // OutputEvent describes a single command result in a structured way.
-type OutputEvent struct {
-	Command         string `json:"command"`
-	Action          string `json:"action"`
-	WindowID        int    `json:"window_id"`
-	AppName         string `json:"app_name"`
-	Workspace       string `json:"workspace"`
-	TargetWorkspace string `json:"target_workspace"`
-	Result          string `json:"result"`
-	Message         string `json:"message"`
-}
+// It is the public scratchpad.Event so the CLI and library share one schema.
+type OutputEvent = scratchpad.Event
 
 // OutputFormatter writes events in a script-friendly format.
 type OutputFormatter struct {
@@ -52,7 +47,10 @@ func NewOutputFormatter(w io.Writer, format string) (*OutputFormatter, error) {
 	}
 }
 
+// Print writes the event, stamped with the current schema version.
 func (f *OutputFormatter) Print(event OutputEvent) error {
+	event.SchemaVersion = scratchpad.EventSchemaVersion
+
 	switch f.format {
 	case OutputFormatJSON:
 		return f.printJSON(event)
@@ -106,17 +104,20 @@ func (f *OutputFormatter) printSeparated(event OutputEvent, sep rune) error {
 	return writer.Error()
 }
 
+// rowValues returns the event fields in the order of outputHeaders.
 func (f *OutputFormatter) rowValues(event OutputEvent) []string {
-	return []string{
-		event.Command,
-		event.Action,
-		strconv.Itoa(event.WindowID),
-		event.AppName,
-		event.Workspace,
-		event.TargetWorkspace,
-		event.Result,
-		event.Message,
+	value := reflect.ValueOf(event)
+	values := make([]string, 0, value.NumField())
+	for i := range value.NumField() {
+		field := value.Field(i)
+		switch field.Kind() { //nolint:exhaustive // the event only holds strings and integers
+		case reflect.Int, reflect.Int64:
+			values = append(values, strconv.FormatInt(field.Int(), 10))
+		default:
+			values = append(values, field.String())
+		}
 	}
+	return values
 }
 
 func quoteIfNeeded(value string) string {
@@ -131,13 +132,17 @@ func quoteIfNeeded(value string) string {
 	return value
 }
 
-var outputHeaders = []string{ //nolint:gochecknoglobals // shared header ordering for all formats
-	"command",
-	"action",
-	"window_id",
-	"app_name",
-	"workspace",
-	"target_workspace",
-	"result",
-	"message",
+// outputHeaders are the JSON keys of the event in field order. Fields are
+// only ever appended to the event, so the columns of older versions keep
+// their position.
+var outputHeaders = eventHeaders() //nolint:gochecknoglobals // shared header ordering for all formats
+
+func eventHeaders() []string {
+	eventType := reflect.TypeFor[OutputEvent]()
+	headers := make([]string, 0, eventType.NumField())
+	for i := range eventType.NumField() {
+		name, _, _ := strings.Cut(eventType.Field(i).Tag.Get("json"), ",")
+		headers = append(headers, name)
+	}
+	return headers
 }
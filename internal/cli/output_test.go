@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/cli"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
 )
 
 func TestOutputFormatter_Text(t *testing.T) {
@@ -26,6 +27,13 @@ func TestOutputFormatter_Text(t *testing.T) {
 		TargetWorkspace: ".scratchpad",
 		Result:          "ok",
 		Message:         "done",
+		Pool:            "media",
+		Monitor:         "DELL U2720Q",
+		WindowTitle:     "Downloads",
+		AppBundleID:     "com.apple.finder",
+		WindowLayout:    "floating",
+		ParentLayout:    "floating",
+		DurationMs:      12,
 	}
 
 	if err = formatter.Print(event); err != nil {
@@ -33,7 +41,9 @@ func TestOutputFormatter_Text(t *testing.T) {
 	}
 
 	got := strings.TrimSpace(buf.String())
-	expected := `command=move action=to-scratchpad window_id=1234 app_name=Finder workspace=ws1 target_workspace=.scratchpad result=ok message=done`
+	expected := `command=move action=to-scratchpad window_id=1234 app_name=Finder workspace=ws1 target_workspace=.scratchpad result=ok message=done pool=media monitor="DELL U2720Q" ` +
+		`window_title=Downloads app_bundle_id=com.apple.finder window_layout=floating parent_layout=floating ` +
+		`duration_ms=12 schema_version=2`
 	if got != expected {
 		t.Fatalf("text output mismatch:\nwant: %s\ngot:  %s", expected, got)
 	}
@@ -55,11 +65,14 @@ func TestOutputFormatter_JSON(t *testing.T) {
 		TargetWorkspace: "",
 		Result:          "ok",
 		Message:         "",
+		WindowTitle:     "kitty work",
+		WindowLayout:    "h_tiles",
 	}
 
 	if err = formatter.Print(event); err != nil {
 		t.Fatalf("unexpected error printing event: %v", err)
 	}
+	event.SchemaVersion = scratchpad.EventSchemaVersion
 
 	var decoded cli.OutputEvent
 	if err = json.Unmarshal(buf.Bytes(), &decoded); err != nil {
@@ -81,6 +94,13 @@ func TestOutputFormatter_TSVAndCSV(t *testing.T) {
 		TargetWorkspace: "ws4",
 		Result:          "ok",
 		Message:         "focused",
+		Pool:            "notes",
+		Monitor:         "Built-in",
+		WindowTitle:     "Groceries",
+		AppBundleID:     "com.apple.Notes",
+		WindowLayout:    "floating",
+		ParentLayout:    "floating",
+		DurationMs:      3,
 	}
 
 	tests := []struct {
@@ -101,6 +121,14 @@ func TestOutputFormatter_TSVAndCSV(t *testing.T) {
 		"ws4",
 		"ok",
 		"focused",
+		"notes",
+		"Built-in",
+		"Groceries",
+		"com.apple.Notes",
+		"floating",
+		"floating",
+		"3",
+		"2",
 	}
 
 	for _, tc := range tests {
@@ -135,6 +163,14 @@ func TestOutputFormatter_TSVAndCSV(t *testing.T) {
 				"target_workspace",
 				"result",
 				"message",
+				"pool",
+				"monitor",
+				"window_title",
+				"app_bundle_id",
+				"window_layout",
+				"parent_layout",
+				"duration_ms",
+				"schema_version",
 			}
 			if !equalStringSlices(rows[0], expectedHeader) {
 				t.Fatalf("header mismatch:\nwant: %v\ngot:  %v", expectedHeader, rows[0])
@@ -170,7 +206,8 @@ func TestOutputFormatter_EmptyFieldsQuotedInText(t *testing.T) {
 	}
 
 	got := strings.TrimSpace(buf.String())
-	expected := `command=move action=to-scratchpad window_id=0 app_name="" workspace="" target_workspace="" result=ok message=""`
+	expected := `command=move action=to-scratchpad window_id=0 app_name="" workspace="" target_workspace="" result=ok message="" pool="" monitor="" ` +
+		`window_title="" app_bundle_id="" window_layout="" parent_layout="" duration_ms=0 schema_version=2`
 	if got != expected {
 		t.Fatalf("text output mismatch for empty fields:\nwant: %s\ngot:  %s", expected, got)
 	}
@@ -206,6 +243,14 @@ func TestOutputFormatter_EmptyFieldsInSeparatedOutputs(t *testing.T) {
 		"",
 		"ok",
 		"",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"0",
+		"2",
 	}
 
 	for _, tc := range tests {
@@ -0,0 +1,321 @@
+// Package config loads the aerospace-scratchpad configuration file.
+//
+// The file is optional JSON, looked up in:
+//   - $AEROSPACE_SCRATCHPAD_CONFIG
+//   - $XDG_CONFIG_HOME/aerospace-scratchpad/config.json
+//   - ~/.config/aerospace-scratchpad/config.json
+//
+// Example:
+//
+//	{
+//	  "timeout": "2s",
+//	  "per_workspace": true,
+//	  "sticky": false,
+//	  "never_focus_workspaces": ["^stash$"],
+//	  "auto_hide": {"patterns": ["^Spotify$"], "grace_period": "300ms"},
+//	  "stash_rules": [{"app_bundle_id": "^com\\.1password\\.", "pool": "secrets"}],
+//	  "scripts": {"on_hide": "osascript -e 'tell app \"Spotify\" to pause'", "timeout": "1s"},
+//	  "show": {"bring": "one", "after_hide": "previous"}
+//	}
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+)
+
+// DefaultGracePeriod is how long the focus-changed hook waits before hiding
+// windows, so quick focus round trips do not make them flicker.
+const DefaultGracePeriod = 250 * time.Millisecond
+
+// DefaultScriptTimeout is how long a script hook may run before being
+// killed, so a stuck script never lingers after the command.
+const DefaultScriptTimeout = time.Second
+
+// DefaultTimeout is how long a command waits for AeroSpace when no timeout
+// is configured. Commands run from hotkeys, they must never hang.
+const DefaultTimeout = 5 * time.Second
+
+// Config holds the user configuration.
+type Config struct {
+	// Timeout bounds the whole command, e.g. "2s". "0s" disables it.
+	Timeout Duration `json:"timeout"`
+	// PerWorkspace stashes windows in a scratchpad owned by their workspace
+	PerWorkspace bool `json:"per_workspace"`
+	// Sticky records the windows brought out in the session state, for `hook
+	// follow`, `hook focus-changed` and the refocus after hiding them.
+	// Enabled by default.
+	Sticky bool `json:"sticky"`
+	// NeverFocusWorkspaces are regexes of hidden workspaces the pull-window
+	// hook pulls windows out of, on top of the scratchpad workspaces
+	NeverFocusWorkspaces []string `json:"never_focus_workspaces"`
+	// AutoHide configures the focus-changed hook
+	AutoHide AutoHide `json:"auto_hide"`
+	// StashRules are the windows the window-detected hook stashes as soon as
+	// they open
+	StashRules []StashRule `json:"stash_rules"`
+	// Scripts are shell commands run after the commands apply an action
+	Scripts Scripts `json:"scripts"`
+	// Show selects the toggle semantics of `show`, flags take precedence
+	Show Show `json:"show"`
+	// Quota bounds the windows of each scratchpad, `move` evicts the excess
+	Quota Quota `json:"quota"`
+}
+
+// Quota bounds the windows of each scratchpad. Empty values keep the
+// default behavior, a zero max_windows disables it.
+type Quota struct {
+	// MaxWindows is the most windows a scratchpad holds
+	MaxWindows int `json:"max_windows"`
+	// Evict is "oldest-stashed" or "least-recently-shown"
+	Evict string `json:"evict"`
+	// Action is "return", "close" or "tile"
+	Action string `json:"action"`
+}
+
+// EvictionPolicy returns the quota as understood by the planner.
+func (q Quota) EvictionPolicy() aerospace.EvictionPolicy {
+	return aerospace.EvictionPolicy{
+		MaxWindows: q.MaxWindows,
+		Evict:      q.Evict,
+		Action:     q.Action,
+	}
+}
+
+// Show selects how `show` brings, focuses and hides the windows. Empty
+// values keep the default behavior.
+type Show struct {
+	// Bring is "all" or "one"
+	Bring string `json:"bring"`
+	// HidePolicy is "all-matching" or "focused-only"
+	HidePolicy string `json:"hide_policy"`
+	// Focus is "last", "first" or "none"
+	Focus string `json:"focus"`
+	// AfterHide is "previous", "dfs-next" or "none"
+	AfterHide string `json:"after_hide"`
+	// ToMonitor brings the windows to the workspace visible on a monitor
+	// unless a target flag is given: "focused", "mouse" or a monitor ID
+	ToMonitor string `json:"to_monitor"`
+}
+
+// TogglePolicy returns the policies as understood by the planner.
+func (s Show) TogglePolicy() aerospace.TogglePolicy {
+	return aerospace.TogglePolicy{
+		Bring:     s.Bring,
+		Hide:      s.HidePolicy,
+		Focus:     s.Focus,
+		AfterHide: s.AfterHide,
+	}
+}
+
+// Scripts are shell commands run with `/bin/sh -c` for each event applied
+// by the commands, with the event as JSON on stdin.
+type Scripts struct {
+	// OnMove runs when `move` stashes a window
+	OnMove string `json:"on_move"`
+	// OnShow runs when a window is brought to a workspace, except by `summon`
+	OnShow string `json:"on_show"`
+	// OnSummon runs when `summon` brings a window to the focused workspace
+	OnSummon string `json:"on_summon"`
+	// OnHide runs when a window is stashed, except by `move`
+	OnHide string `json:"on_hide"`
+	// Timeout is how long the scripts may run, e.g. "500ms"
+	Timeout Duration `json:"timeout"`
+}
+
+// AutoHide selects the floating scratchpad windows hidden when they lose
+// the focus. Nothing is hidden unless All or Patterns is set.
+type AutoHide struct {
+	// All hides every floating window losing the focus
+	All bool `json:"all"`
+	// Patterns are regexes of the app names to hide, e.g. "^Spotify$"
+	Patterns []string `json:"patterns"`
+	// GracePeriod is how long to wait before hiding, e.g. "300ms"
+	GracePeriod Duration `json:"grace_period"`
+}
+
+// StashRule matches new windows by regexes on their properties, every set
+// property must match.
+type StashRule struct {
+	// AppName is a regex of the app name, e.g. "^Spotify$"
+	AppName string `json:"app_name"`
+	// AppBundleID is a regex of the app bundle ID, e.g. `^com\.spotify\.`
+	AppBundleID string `json:"app_bundle_id"`
+	// WindowTitle is a regex of the window title
+	WindowTitle string `json:"window_title"`
+	// Pool is the scratchpad pool the windows go to, the shared one if empty
+	Pool string `json:"pool"`
+}
+
+// Filters returns the rule in the `--filter` format, e.g. "app-name=^Spotify$".
+func (r StashRule) Filters() []string {
+	var filters []string
+	for _, property := range []struct{ name, pattern string }{
+		{"app-name", r.AppName},
+		{"app-bundle-id", r.AppBundleID},
+		{"window-title", r.WindowTitle},
+	} {
+		if property.pattern != "" {
+			filters = append(filters, property.name+"="+property.pattern)
+		}
+	}
+	return filters
+}
+
+// Duration is a time.Duration written as a string in the config, e.g. "1.5s".
+type Duration time.Duration
+
+// UnmarshalJSON parses durations like "500ms" or "2s".
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string like \"2s\": %w", err)
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+	if parsed < 0 {
+		return fmt.Errorf("invalid duration %q: must not be negative", value)
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration in the same format it is read.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Duration returns the value as a time.Duration.
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// Default returns the configuration used when no file exists.
+func Default() Config {
+	return Config{
+		Timeout: Duration(DefaultTimeout),
+		Sticky:  true,
+		AutoHide: AutoHide{
+			GracePeriod: Duration(DefaultGracePeriod),
+		},
+		Scripts: Scripts{
+			Timeout: Duration(DefaultScriptTimeout),
+		},
+	}
+}
+
+// Path returns the location of the configuration file.
+func Path() (string, error) {
+	if path := os.Getenv(constants.EnvAeroSpaceScratchpadConfig); path != "" {
+		return path, nil
+	}
+
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to find home directory: %w", err)
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(configDir, "aerospace-scratchpad", "config.json"), nil
+}
+
+// Load reads the configuration file, falling back to the defaults when it
+// does not exist.
+func Load() (Config, error) {
+	path, err := Path()
+	if err != nil {
+		return Default(), err
+	}
+
+	return LoadFile(path)
+}
+
+// LoadFile reads the configuration from path. Missing keys keep their
+// default value.
+func LoadFile(path string) (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("unable to read config '%s': %w", path, err)
+	}
+
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return Default(), fmt.Errorf("invalid config '%s': %w", path, err)
+	}
+
+	if err = cfg.validate(); err != nil {
+		return Default(), fmt.Errorf("invalid config '%s': %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// validate checks the values json.Unmarshal cannot.
+func (c Config) validate() error {
+	for _, pattern := range c.NeverFocusWorkspaces {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid never_focus_workspaces pattern %q: %w", pattern, err)
+		}
+	}
+
+	for _, pattern := range c.AutoHide.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid auto_hide pattern %q: %w", pattern, err)
+		}
+	}
+
+	for i, rule := range c.StashRules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid stash_rules[%d]: %w", i, err)
+		}
+	}
+
+	if err := c.Show.TogglePolicy().Validate(); err != nil {
+		return fmt.Errorf("invalid show: %w", err)
+	}
+	if c.Show.ToMonitor != "" {
+		if err := aerospace.ValidateMonitor(c.Show.ToMonitor); err != nil {
+			return fmt.Errorf("invalid show: %w", err)
+		}
+	}
+
+	if err := c.Quota.EvictionPolicy().Validate(); err != nil {
+		return fmt.Errorf("invalid quota: %w", err)
+	}
+
+	return nil
+}
+
+func (r StashRule) validate() error {
+	if r.AppName == "" && r.AppBundleID == "" && r.WindowTitle == "" {
+		return errors.New("app_name, app_bundle_id or window_title is required")
+	}
+
+	for _, pattern := range []string{r.AppName, r.AppBundleID, r.WindowTitle} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
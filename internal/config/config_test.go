@@ -0,0 +1,222 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/config"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("unable to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Run("returns defaults when the file does not exist", func(t *testing.T) {
+		cfg, err := config.LoadFile(filepath.Join(t.TempDir(), "missing.json"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Timeout.Duration() != config.DefaultTimeout {
+			t.Errorf("expected default timeout, got %v", cfg.Timeout.Duration())
+		}
+		if cfg.Quota.EvictionPolicy().Enabled() {
+			t.Errorf("expected no quota, got %+v", cfg.Quota)
+		}
+		if !cfg.Sticky {
+			t.Errorf("expected sticky to be enabled by default")
+		}
+	})
+
+	t.Run("reads the timeout", func(t *testing.T) {
+		cfg, err := config.LoadFile(writeConfig(t, `{"timeout": "1500ms"}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Timeout.Duration() != 1500*time.Millisecond {
+			t.Errorf("expected 1.5s, got %v", cfg.Timeout.Duration())
+		}
+	})
+
+	t.Run("reads per_workspace", func(t *testing.T) {
+		cfg, err := config.LoadFile(writeConfig(t, `{"per_workspace": true}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !cfg.PerWorkspace {
+			t.Errorf("expected per_workspace to be enabled")
+		}
+	})
+
+	t.Run("reads sticky", func(t *testing.T) {
+		cfg, err := config.LoadFile(writeConfig(t, `{"sticky": false}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Sticky {
+			t.Errorf("expected sticky to be disabled")
+		}
+	})
+
+	t.Run("reads never_focus_workspaces", func(t *testing.T) {
+		cfg, err := config.LoadFile(writeConfig(t, `{"never_focus_workspaces": ["^stash$", "^hidden-"]}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(cfg.NeverFocusWorkspaces) != 2 || cfg.NeverFocusWorkspaces[0] != "^stash$" {
+			t.Errorf("unexpected never_focus_workspaces %v", cfg.NeverFocusWorkspaces)
+		}
+	})
+
+	t.Run("reads auto_hide", func(t *testing.T) {
+		cfg, err := config.LoadFile(writeConfig(t, `{"auto_hide": {"patterns": ["^Spotify$"]}}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(cfg.AutoHide.Patterns) != 1 || cfg.AutoHide.All {
+			t.Errorf("unexpected auto_hide %+v", cfg.AutoHide)
+		}
+		if cfg.AutoHide.GracePeriod.Duration() != config.DefaultGracePeriod {
+			t.Errorf("expected default grace period, got %v", cfg.AutoHide.GracePeriod.Duration())
+		}
+	})
+
+	t.Run("reads stash_rules as filters", func(t *testing.T) {
+		cfg, err := config.LoadFile(writeConfig(
+			t,
+			`{"stash_rules": [{"app_name": "^Spotify$", "window_title": "=", "pool": "media"}]}`,
+		))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(cfg.StashRules) != 1 || cfg.StashRules[0].Pool != "media" {
+			t.Fatalf("unexpected stash_rules %+v", cfg.StashRules)
+		}
+		filters := cfg.StashRules[0].Filters()
+		if !reflect.DeepEqual(filters, []string{"app-name=^Spotify$", "window-title=="}) {
+			t.Errorf("unexpected filters %v", filters)
+		}
+	})
+
+	t.Run("reads scripts", func(t *testing.T) {
+		cfg, err := config.LoadFile(writeConfig(t, `{"scripts": {"on_hide": "echo hidden"}}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Scripts.OnHide != "echo hidden" {
+			t.Errorf("unexpected scripts %+v", cfg.Scripts)
+		}
+		if cfg.Scripts.Timeout.Duration() != config.DefaultScriptTimeout {
+			t.Errorf("expected default script timeout, got %v", cfg.Scripts.Timeout.Duration())
+		}
+	})
+
+	t.Run("reads show policies", func(t *testing.T) {
+		cfg, err := config.LoadFile(writeConfig(
+			t,
+			`{"show": {"bring": "one", "after_hide": "previous", "to_monitor": "mouse"}}`,
+		))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		policy := cfg.Show.TogglePolicy()
+		if policy.Bring != "one" || policy.AfterHide != "previous" || policy.Focus != "" {
+			t.Errorf("unexpected show policies %+v", policy)
+		}
+		if cfg.Show.ToMonitor != "mouse" {
+			t.Errorf("expected the mouse monitor, got %q", cfg.Show.ToMonitor)
+		}
+	})
+
+	t.Run("reads the quota", func(t *testing.T) {
+		cfg, err := config.LoadFile(writeConfig(
+			t,
+			`{"quota": {"max_windows": 3, "evict": "least-recently-shown", "action": "close"}}`,
+		))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		policy := cfg.Quota.EvictionPolicy()
+		if !policy.Enabled() || policy.MaxWindows != 3 ||
+			policy.Evict != "least-recently-shown" || policy.Action != "close" {
+			t.Errorf("unexpected quota %+v", policy)
+		}
+	})
+
+	t.Run("keeps defaults for missing keys", func(t *testing.T) {
+		cfg, err := config.LoadFile(writeConfig(t, `{}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Timeout.Duration() != config.DefaultTimeout {
+			t.Errorf("expected default timeout, got %v", cfg.Timeout.Duration())
+		}
+	})
+
+	t.Run("fails on invalid values", func(t *testing.T) {
+		for _, content := range []string{
+			`{"timeout": "soon"}`,
+			`{"timeout": 10}`,
+			`{"timeout": "-1s"}`,
+			`{"never_focus_workspaces": ["*stash"]}`,
+			`{"auto_hide": {"patterns": ["*Spotify"]}}`,
+			`{"stash_rules": [{"app_name": "*Spotify"}]}`,
+			`{"stash_rules": [{"pool": "media"}]}`,
+			`{"show": {"hide_policy": "everything"}}`,
+			`{"show": {"to_monitor": "left"}}`,
+			`{"quota": {"max_windows": -1}}`,
+			`{"quota": {"max_windows": 3, "action": "minimize"}}`,
+		} {
+			if _, err := config.LoadFile(writeConfig(t, content)); err == nil {
+				t.Errorf("expected error for %s", content)
+			}
+		}
+	})
+}
+
+func TestPath(t *testing.T) {
+	t.Run("prefers the environment variable", func(t *testing.T) {
+		t.Setenv("AEROSPACE_SCRATCHPAD_CONFIG", "/tmp/custom.json")
+
+		path, err := config.Path()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if path != "/tmp/custom.json" {
+			t.Errorf("expected env path, got %s", path)
+		}
+	})
+
+	t.Run("uses XDG_CONFIG_HOME", func(t *testing.T) {
+		t.Setenv("AEROSPACE_SCRATCHPAD_CONFIG", "")
+		t.Setenv("XDG_CONFIG_HOME", "/xdg")
+
+		path, err := config.Path()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if path != "/xdg/aerospace-scratchpad/config.json" {
+			t.Errorf("unexpected path %s", path)
+		}
+	})
+}
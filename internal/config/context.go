@@ -0,0 +1,19 @@
+package config
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the configuration.
+func NewContext(ctx context.Context, cfg Config) context.Context {
+	return context.WithValue(ctx, contextKey{}, cfg)
+}
+
+// FromContext returns the configuration carried by ctx, the defaults when
+// there is none.
+func FromContext(ctx context.Context) Config {
+	if cfg, ok := ctx.Value(contextKey{}).(Config); ok {
+		return cfg
+	}
+	return Default()
+}
@@ -4,7 +4,4 @@ const (
 	// DefaultScratchpadWorkspaceName is the default name of the workspace
 	// for the scratchpad.
 	DefaultScratchpadWorkspaceName = ".scratchpad"
-
-	// Temporary file to indicate that we're moving the scratchpad.
-	TempScratchpadMovingFile string = "/tmp/.aerospace-scratchpad-moving"
 )
@@ -11,6 +11,14 @@ const (
 	// default: `DISABLED`
 	EnvAeroSpaceScratchpadLogsLevel string = "AEROSPACE_SCRATCHPAD_LOGS_LEVEL"
 
+	// EnvAeroSpaceScratchpadConfig is the environment variable for the config file path
+	// default: `$XDG_CONFIG_HOME/aerospace-scratchpad/config.json`
+	EnvAeroSpaceScratchpadConfig string = "AEROSPACE_SCRATCHPAD_CONFIG"
+
 	// EnvAeroSpaceSock is the environment variable for the AeroSpace IPC socket path.
 	EnvAeroSpaceSock string = "AEROSPACESOCK"
+
+	// EnvSecuritySessionID is set by macOS to the login session, used to
+	// scope the moving marker.
+	EnvSecuritySessionID string = "SECURITYSESSIONID"
 )
@@ -0,0 +1,138 @@
+// Package eventlog implements the local channel `watch` streams events from.
+//
+// Commands and hooks append the events they applied to a newline-delimited
+// JSON log, per user and login session. Watchers tail it from its end, so
+// they only see the events emitted after they started. The log is truncated
+// when it grows past MaxSize, watchers notice and start over.
+package eventlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
+)
+
+// MaxSize is the size past which the log is truncated before appending.
+const MaxSize = 1 << 20
+
+// DefaultPath returns the log of the current user and session.
+func DefaultPath() string {
+	return state.SessionFile("events")
+}
+
+// Append writes the events at the end of the log, one JSON object per line.
+func Append(path string, events []scratchpad.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("unable to create event log directory: %w", err)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if info, err := os.Stat(path); err == nil && info.Size() > MaxSize {
+		flags |= os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(path, flags, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to open event log: %w", err)
+	}
+	defer file.Close()
+
+	// A single write, so concurrent commands never interleave their lines
+	if _, err = file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("unable to write event log: %w", err)
+	}
+
+	return nil
+}
+
+// Tail reads the events appended to a log.
+type Tail struct {
+	path   string
+	offset int64
+}
+
+// NewTail returns a Tail starting at the current end of the log, a missing
+// log is read from its beginning once created.
+func NewTail(path string) (*Tail, error) {
+	tail := &Tail{path: path}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return tail, nil
+		}
+		return nil, fmt.Errorf("unable to read event log: %w", err)
+	}
+
+	tail.offset = info.Size()
+	return tail, nil
+}
+
+// Next returns the events appended since the last call. Lines still being
+// written are left for the next call and unreadable lines are skipped.
+func (t *Tail) Next() ([]scratchpad.Event, error) {
+	file, err := os.Open(t.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			t.offset = 0
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read event log: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read event log: %w", err)
+	}
+	if info.Size() < t.offset {
+		// Truncated, everything in it is new
+		t.offset = 0
+	}
+
+	if _, err = file.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to read event log: %w", err)
+	}
+
+	var events []scratchpad.Event
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil {
+			// Incomplete lines are read again once finished
+			if errors.Is(readErr, io.EOF) {
+				return events, nil
+			}
+			return events, fmt.Errorf("unable to read event log: %w", readErr)
+		}
+		t.offset += int64(len(line))
+
+		var event scratchpad.Event
+		if json.Unmarshal(line, &event) == nil {
+			events = append(events, event)
+		}
+	}
+}
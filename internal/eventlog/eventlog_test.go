@@ -0,0 +1,104 @@
+package eventlog_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/eventlog"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
+)
+
+func TestTail(t *testing.T) {
+	shown := scratchpad.Event{Command: "show", Action: "to-workspace", WindowID: 1, Result: "ok"}
+	hidden := scratchpad.Event{Command: "show", Action: "to-scratchpad", WindowID: 1, Result: "ok"}
+
+	t.Run("reads the events appended after it started", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "user", "events")
+		if err := eventlog.Append(path, []scratchpad.Event{hidden}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tail, err := eventlog.NewTail(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err = eventlog.Append(path, []scratchpad.Event{shown, hidden}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		events, err := tail.Next()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(events, []scratchpad.Event{shown, hidden}) {
+			t.Fatalf("expected the new events, got %+v", events)
+		}
+
+		if events, _ = tail.Next(); len(events) != 0 {
+			t.Fatalf("expected no more events, got %+v", events)
+		}
+	})
+
+	t.Run("waits for the log to be created", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events")
+
+		tail, err := eventlog.NewTail(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if events, _ := tail.Next(); len(events) != 0 {
+			t.Fatalf("expected no events, got %+v", events)
+		}
+
+		if err = eventlog.Append(path, []scratchpad.Event{shown}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if events, _ := tail.Next(); !reflect.DeepEqual(events, []scratchpad.Event{shown}) {
+			t.Fatalf("expected the new event, got %+v", events)
+		}
+	})
+
+	t.Run("leaves incomplete lines for later and skips unreadable ones", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events")
+		tail, _ := eventlog.NewTail(path)
+
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer file.Close()
+
+		_, _ = file.WriteString("garbage\n{\"command\":\"show\",")
+		if events, _ := tail.Next(); len(events) != 0 {
+			t.Fatalf("expected no events, got %+v", events)
+		}
+
+		_, _ = file.WriteString("\"window_id\":1}\n")
+		events, _ := tail.Next()
+		if len(events) != 1 || events[0].Command != "show" || events[0].WindowID != 1 {
+			t.Fatalf("expected the completed event, got %+v", events)
+		}
+	})
+
+	t.Run("starts over when the log is truncated", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events")
+		big := make([]scratchpad.Event, 0, eventlog.MaxSize/64)
+		for range cap(big) {
+			big = append(big, hidden)
+		}
+		if err := eventlog.Append(path, big); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tail, _ := eventlog.NewTail(path)
+		if err := eventlog.Append(path, []scratchpad.Event{shown}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if events, _ := tail.Next(); !reflect.DeepEqual(events, []scratchpad.Event{shown}) {
+			t.Fatalf("expected the event after truncation, got %+v", events)
+		}
+	})
+}
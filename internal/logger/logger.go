@@ -161,6 +161,14 @@ func SetDefaultLogger(logger Logger) {
 	defaultLogger = logger
 }
 
+// EnsureDefaultLogger sets the given logger only when none was set yet.
+// Useful for library entry points that must work without CLI setup.
+func EnsureDefaultLogger(fallback Logger) {
+	if defaultLogger == nil {
+		defaultLogger = fallback
+	}
+}
+
 func GetDefaultLogger() Logger {
 	if defaultLogger == nil {
 		panic("Unrecoverable error because default logger is not set")
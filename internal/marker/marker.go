@@ -0,0 +1,156 @@
+// Package marker implements the moving marker protocol.
+//
+// Commands moving scratchpad windows create the marker right before talking
+// to AeroSpace. The pull-window hook, run by AeroSpace on the workspace
+// changes those moves cause, consumes it and leaves the windows alone. Any
+// other workspace change, e.g. a launcher focusing a hidden window, finds no
+// marker.
+//
+// The marker is per user and per login session, and expires on its own so a
+// move that caused no workspace change never silences the hook for long.
+package marker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+)
+
+// DefaultTTL is how long a marker silences the hook after being created.
+const DefaultTTL = 2 * time.Second
+
+// Content is what the marker file holds.
+type Content struct {
+	// PID is the process that created the marker, for troubleshooting
+	PID int `json:"pid"`
+	// ExpiresAt is when the marker stops silencing the hook
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Marker is the moving marker of the current user and session.
+type Marker struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// New returns the marker of the current user and session.
+func New() *Marker {
+	return NewAt(DefaultPath(), DefaultTTL)
+}
+
+// NewAt returns a marker stored at path that expires after ttl.
+func NewAt(path string, ttl time.Duration) *Marker {
+	return &Marker{
+		path: path,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// DefaultPath returns the marker location, in a directory owned by the
+// current user and named after the login session, e.g.
+// `$TMPDIR/aerospace-scratchpad-501/moving-<session>`.
+func DefaultPath() string {
+	return state.SessionFile("moving")
+}
+
+// Path returns where the marker is stored.
+func (m *Marker) Path() string {
+	return m.path
+}
+
+// Create writes the marker, replacing any previous one.
+func (m *Marker) Create() error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create marker directory: %w", err)
+	}
+
+	data, err := json.Marshal(Content{
+		PID:       os.Getpid(),
+		ExpiresAt: m.now().Add(m.ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Write then rename, so the hook never reads a partial marker
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), filepath.Base(m.path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to write marker: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write marker: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write marker: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), m.path); err != nil {
+		return fmt.Errorf("unable to write marker: %w", err)
+	}
+
+	return nil
+}
+
+// Read returns the marker content. The boolean is false when there is no
+// marker.
+func (m *Marker) Read() (Content, bool, error) {
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Content{}, false, nil
+		}
+		return Content{}, false, err
+	}
+
+	var content Content
+	if err = json.Unmarshal(data, &content); err != nil {
+		// Unreadable markers, e.g. from older versions, are stale
+		return Content{}, true, nil
+	}
+
+	return content, true, nil
+}
+
+// Active reports whether a marker exists and has not expired.
+func (m *Marker) Active() (bool, error) {
+	content, exists, err := m.Read()
+	if err != nil || !exists {
+		return false, err
+	}
+
+	return m.now().Before(content.ExpiresAt), nil
+}
+
+// Remove deletes the marker, if any.
+func (m *Marker) Remove() error {
+	err := os.Remove(m.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Consume reports whether an active marker existed and removes the marker,
+// active or stale.
+func (m *Marker) Consume() (bool, error) {
+	active, err := m.Active()
+	if err != nil {
+		return false, err
+	}
+
+	if err = m.Remove(); err != nil {
+		return active, err
+	}
+
+	return active, nil
+}
@@ -0,0 +1,109 @@
+package marker_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/marker"
+)
+
+func TestMarker(t *testing.T) {
+	t.Run("is active until consumed", func(t *testing.T) {
+		moving := marker.NewAt(filepath.Join(t.TempDir(), "user", "moving"), time.Minute)
+		if err := moving.Create(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		content, exists, err := moving.Read()
+		if err != nil || !exists {
+			t.Fatalf("expected marker, got exists=%v err=%v", exists, err)
+		}
+		if content.PID != os.Getpid() {
+			t.Errorf("expected pid %d, got %d", os.Getpid(), content.PID)
+		}
+
+		active, err := moving.Consume()
+		if err != nil || !active {
+			t.Fatalf("expected an active marker, got active=%v err=%v", active, err)
+		}
+
+		active, err = moving.Consume()
+		if err != nil || active {
+			t.Fatalf("expected the marker to be consumed, got active=%v err=%v", active, err)
+		}
+	})
+
+	t.Run("expires on its own", func(t *testing.T) {
+		moving := marker.NewAt(filepath.Join(t.TempDir(), "moving"), -time.Second)
+		if err := moving.Create(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		active, err := moving.Consume()
+		if err != nil || active {
+			t.Fatalf("expected a stale marker, got active=%v err=%v", active, err)
+		}
+		if _, exists, _ := moving.Read(); exists {
+			t.Fatalf("expected the stale marker to be removed")
+		}
+	})
+
+	t.Run("stays readable when created concurrently", func(t *testing.T) {
+		dir := t.TempDir()
+		moving := marker.NewAt(filepath.Join(dir, "moving"), time.Minute)
+
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := moving.Create(); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		active, err := moving.Active()
+		if err != nil || !active {
+			t.Fatalf("expected an active marker, got active=%v err=%v", active, err)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("expected only the marker to be left, got %v err=%v", entries, err)
+		}
+	})
+
+	t.Run("treats unreadable markers as stale", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "moving")
+		if err := os.WriteFile(path, []byte("moving"), 0o600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		active, err := marker.NewAt(path, time.Minute).Active()
+		if err != nil || active {
+			t.Fatalf("expected a stale marker, got active=%v err=%v", active, err)
+		}
+	})
+}
+
+func TestDefaultPath(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	t.Setenv("SECURITYSESSIONID", "a/b")
+
+	path := marker.DefaultPath()
+	if !strings.HasPrefix(path, tmp) {
+		t.Errorf("expected marker in %s, got %s", tmp, path)
+	}
+	if filepath.Base(path) != "moving-a_b" {
+		t.Errorf("expected the marker to be named after the session, got %s", path)
+	}
+	if !strings.Contains(path, "aerospace-scratchpad-") {
+		t.Errorf("expected a per-user directory, got %s", path)
+	}
+}
@@ -0,0 +1,163 @@
+// Package scripts runs the user script hooks of the configuration after the
+// commands apply an action.
+//
+// Scripts never fail nor delay the command: they start detached in their own
+// process group, are killed after the configured timeout and only failing to
+// start them is logged.
+package scripts
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/config"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
+)
+
+// Script hook names, as in the configuration.
+const (
+	HookOnMove   = "on_move"
+	HookOnShow   = "on_show"
+	HookOnSummon = "on_summon"
+	HookOnHide   = "on_hide"
+)
+
+// Shell runs the scripts.
+const Shell = "/bin/sh"
+
+// watchdog runs the script in $2 and kills its whole process group once $1
+// seconds elapsed, so the timeout holds with nobody waiting for the script.
+const watchdog = `(sleep "$1"; kill -TERM 0) &
+watchdog=$!
+eval "$2"
+status=$?
+kill "$watchdog" 2>/dev/null
+exit "$status"`
+
+// Runner runs the scripts of the configuration.
+type Runner struct {
+	scripts config.Scripts
+	logger  logger.Logger
+}
+
+// New returns a Runner of the given scripts.
+func New(scripts config.Scripts) *Runner {
+	return &Runner{
+		scripts: scripts,
+		logger:  logger.GetDefaultLogger(),
+	}
+}
+
+// HookFor returns the hook run for the event, empty when none is.
+func HookFor(event scratchpad.Event) string {
+	if event.Result != "ok" {
+		return ""
+	}
+
+	switch event.Action {
+	case "to-scratchpad":
+		if event.Command == "move" {
+			return HookOnMove
+		}
+		return HookOnHide
+	case "to-workspace":
+		if event.Command == "summon" {
+			return HookOnSummon
+		}
+		return HookOnShow
+	default:
+		return ""
+	}
+}
+
+func (r *Runner) script(hook string) string {
+	switch hook {
+	case HookOnMove:
+		return r.scripts.OnMove
+	case HookOnShow:
+		return r.scripts.OnShow
+	case HookOnSummon:
+		return r.scripts.OnSummon
+	case HookOnHide:
+		return r.scripts.OnHide
+	default:
+		return ""
+	}
+}
+
+// Run starts the script of each event without waiting for them, a hotkey
+// command never stays open for its scripts. The scripts outlive ctx and the
+// command itself, but not their own timeout.
+func (r *Runner) Run(ctx context.Context, events []scratchpad.Event) {
+	ctx = context.WithoutCancel(ctx)
+	for _, event := range events {
+		hook := HookFor(event)
+		if script := r.script(hook); script != "" {
+			r.start(ctx, hook, script, event)
+		}
+	}
+}
+
+func (r *Runner) start(ctx context.Context, hook string, script string, event scratchpad.Event) {
+	input, err := json.Marshal(event)
+	if err != nil {
+		r.logger.LogError("SCRIPTS: unable to encode event", "hook", hook, "error", err)
+		return
+	}
+
+	args := []string{"-c", script}
+	if timeout := r.scripts.Timeout.Duration(); timeout > 0 {
+		seconds := strconv.FormatFloat(timeout.Seconds(), 'f', -1, 64)
+		args = []string{"-c", watchdog, "scratchpad-script", seconds, script}
+	}
+
+	// The event is written to a pipe rather than copied by a goroutine, which
+	// would not outlive the command
+	stdin, writer, err := os.Pipe()
+	if err != nil {
+		r.logger.LogError("SCRIPTS: unable to pass the event", "hook", hook, "error", err)
+		return
+	}
+	defer stdin.Close()
+	defer writer.Close()
+
+	cmd := exec.CommandContext(ctx, Shell, args...)
+	cmd.Stdin = stdin
+	cmd.Env = append(os.Environ(), Env(hook, event)...)
+	// Its own process group, so the watchdog kills the whole script and
+	// the terminal signals of the command do not reach it
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	r.logger.LogDebug("SCRIPTS: starting script", "hook", hook, "window-id", event.WindowID)
+	if err = cmd.Start(); err != nil {
+		r.logger.LogError("SCRIPTS: unable to start script", "hook", hook, "error", err)
+		return
+	}
+
+	if _, err = writer.Write(input); err != nil {
+		r.logger.LogError("SCRIPTS: unable to pass the event", "hook", hook, "error", err)
+	}
+
+	// Never waited for, the script is reparented once the command exits
+	_ = cmd.Process.Release()
+}
+
+// Env returns the variables describing the event to the script.
+func Env(hook string, event scratchpad.Event) []string {
+	return []string{
+		"SCRATCHPAD_HOOK=" + hook,
+		"SCRATCHPAD_COMMAND=" + event.Command,
+		"SCRATCHPAD_ACTION=" + event.Action,
+		"SCRATCHPAD_WINDOW_ID=" + strconv.Itoa(event.WindowID),
+		"SCRATCHPAD_APP_NAME=" + event.AppName,
+		"SCRATCHPAD_WORKSPACE=" + event.Workspace,
+		"SCRATCHPAD_TARGET_WORKSPACE=" + event.TargetWorkspace,
+		"SCRATCHPAD_POOL=" + event.Pool,
+		"SCRATCHPAD_MONITOR=" + event.Monitor,
+	}
+}
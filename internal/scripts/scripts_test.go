@@ -0,0 +1,129 @@
+package scripts_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/config"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/scripts"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
+)
+
+func TestHookFor(t *testing.T) {
+	for _, tc := range []struct {
+		event    scratchpad.Event
+		expected string
+	}{
+		{scratchpad.Event{Command: "move", Action: "to-scratchpad", Result: "ok"}, scripts.HookOnMove},
+		{scratchpad.Event{Command: "show", Action: "to-scratchpad", Result: "ok"}, scripts.HookOnHide},
+		{scratchpad.Event{Command: "hook", Action: "to-scratchpad", Result: "ok"}, scripts.HookOnHide},
+		{scratchpad.Event{Command: "show", Action: "to-workspace", Result: "ok"}, scripts.HookOnShow},
+		{scratchpad.Event{Command: "next", Action: "to-workspace", Result: "ok"}, scripts.HookOnShow},
+		{scratchpad.Event{Command: "summon", Action: "to-workspace", Result: "ok"}, scripts.HookOnSummon},
+		{scratchpad.Event{Command: "show", Action: "focus", Result: "ok"}, ""},
+		{scratchpad.Event{Command: "show", Action: "to-workspace", Result: "planned"}, ""},
+		{scratchpad.Event{Command: "move", Action: "to-scratchpad", Result: "error"}, ""},
+	} {
+		if hook := scripts.HookFor(tc.event); hook != tc.expected {
+			t.Errorf("expected hook %q for %+v, got %q", tc.expected, tc.event, hook)
+		}
+	}
+}
+
+func TestRunner(t *testing.T) {
+	logger.SetDefaultLogger(&logger.EmptyLogger{})
+	ctx := context.Background()
+
+	t.Run("passes the event on stdin and in the environment", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("OUT", dir)
+		event := scratchpad.Event{
+			Command:   "show",
+			Action:    "to-scratchpad",
+			WindowID:  42,
+			AppName:   "Spotify",
+			Workspace: "ws1",
+			Result:    "ok",
+			Pool:      "media",
+		}
+
+		scripts.New(config.Scripts{
+			OnHide:  `cat > "$OUT/event.json"; echo "$SCRATCHPAD_HOOK $SCRATCHPAD_WINDOW_ID $SCRATCHPAD_POOL" > "$OUT/env"`,
+			OnShow:  `touch "$OUT/show"`,
+			Timeout: config.Duration(5 * time.Second),
+		}).Run(ctx, []scratchpad.Event{event})
+
+		// Written once the event is read
+		env := testutils.ReadFileEventually(t, filepath.Join(dir, "env"), 5*time.Second)
+		if strings.TrimSpace(string(env)) != "on_hide 42 media" {
+			t.Errorf("unexpected environment %q", env)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "event.json"))
+		if err != nil {
+			t.Fatalf("expected the script to run: %v", err)
+		}
+		var received scratchpad.Event
+		if err = json.Unmarshal(data, &received); err != nil || received != event {
+			t.Errorf("expected event %+v on stdin, got %s", event, data)
+		}
+
+		if _, err = os.Stat(filepath.Join(dir, "show")); err == nil {
+			t.Errorf("expected only the on_hide script to run")
+		}
+	})
+
+	t.Run("does not wait for the scripts", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("OUT", dir)
+
+		started := time.Now()
+		scripts.New(config.Scripts{
+			OnMove:  `sleep 1; echo done > "$OUT/done"`,
+			Timeout: config.Duration(5 * time.Second),
+		}).Run(ctx, []scratchpad.Event{{Command: "move", Action: "to-scratchpad", Result: "ok"}})
+
+		if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+			t.Fatalf("expected the script to run detached, waited %s", elapsed)
+		}
+		testutils.ReadFileEventually(t, filepath.Join(dir, "done"), 5*time.Second)
+	})
+
+	t.Run("kills scripts running past the timeout", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("OUT", dir)
+
+		scripts.New(config.Scripts{
+			// The sleep runs in a child process, killed along with the shell
+			OnMove:  `echo started > "$OUT/started"; sleep 1; touch "$OUT/done"`,
+			Timeout: config.Duration(100 * time.Millisecond),
+		}).Run(ctx, []scratchpad.Event{{Command: "move", Action: "to-scratchpad", Result: "ok"}})
+
+		testutils.ReadFileEventually(t, filepath.Join(dir, "started"), 5*time.Second)
+		time.Sleep(1500 * time.Millisecond)
+		if _, err := os.Stat(filepath.Join(dir, "done")); err == nil {
+			t.Fatalf("expected the script to be killed")
+		}
+	})
+
+	t.Run("isolates failing scripts", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("OUT", dir)
+		scripts.New(config.Scripts{
+			OnSummon: `[ "$SCRATCHPAD_WINDOW_ID" = 1 ] && exit 1; echo ok > "$OUT/$SCRATCHPAD_WINDOW_ID"`,
+			Timeout:  config.Duration(5 * time.Second),
+		}).Run(ctx, []scratchpad.Event{
+			{Command: "summon", Action: "to-workspace", WindowID: 1, Result: "ok"},
+			{Command: "summon", Action: "to-workspace", WindowID: 2, Result: "ok"},
+		})
+
+		testutils.ReadFileEventually(t, filepath.Join(dir, "2"), 5*time.Second)
+	})
+}
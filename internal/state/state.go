@@ -0,0 +1,246 @@
+// Package state keeps what commands remember between runs, e.g. the windows
+// shown by `show` that `hook follow` carries along.
+//
+// Like the moving marker, the state is per user and per login session, so it
+// never outlives the windows it refers to by much.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+)
+
+// State is what the state file holds.
+type State struct {
+	// Sticky are the windows shown by `show`, they follow the focused
+	// workspace until hidden
+	Sticky []int `json:"sticky"`
+	// PreviousFocus are the windows focused before `show` brought windows,
+	// by brought window, focused again when they are hidden
+	PreviousFocus map[int]int `json:"previous_focus"`
+	// ShownFrom are the scratchpad workspaces the shown windows were brought
+	// out of, by window, `hook focus-changed` hides them back there
+	ShownFrom map[int]string `json:"shown_from"`
+	// Stash are the scratchpad windows, by window, for the eviction of
+	// `move`. Only recorded while a quota is configured.
+	Stash map[int]StashRecord `json:"stash"`
+}
+
+// StashRecord is what is known about a scratchpad window.
+type StashRecord struct {
+	// Origin is the workspace the window was stashed from
+	Origin string `json:"origin"`
+	// StashedAt is when it was last stashed
+	StashedAt time.Time `json:"stashed_at"`
+	// ShownAt is when it was last shown, zero when never
+	ShownAt time.Time `json:"shown_at"`
+}
+
+// RecordStashed records a window stashed from origin. An empty origin, e.g.
+// when moved between scratchpads, keeps the known one.
+func (s *State) RecordStashed(windowID int, origin string, at time.Time) {
+	if s.Stash == nil {
+		s.Stash = make(map[int]StashRecord)
+	}
+	record := s.Stash[windowID]
+	if origin != "" {
+		record.Origin = origin
+	}
+	record.StashedAt = at
+	s.Stash[windowID] = record
+}
+
+// RecordShown records a window shown, it keeps its record until evicted.
+func (s *State) RecordShown(windowID int, at time.Time) {
+	if s.Stash == nil {
+		s.Stash = make(map[int]StashRecord)
+	}
+	record := s.Stash[windowID]
+	record.ShownAt = at
+	s.Stash[windowID] = record
+}
+
+// ForgetStash forgets an evicted window.
+func (s *State) ForgetStash(windowID int) {
+	delete(s.Stash, windowID)
+}
+
+// SetPreviousFocus records the window focused before a window was shown.
+func (s *State) SetPreviousFocus(windowID, previousID int) {
+	if s.PreviousFocus == nil {
+		s.PreviousFocus = make(map[int]int)
+	}
+	s.PreviousFocus[windowID] = previousID
+}
+
+// ForgetPreviousFocus forgets the previous focus of a hidden window.
+func (s *State) ForgetPreviousFocus(windowID int) {
+	delete(s.PreviousFocus, windowID)
+}
+
+// RecordShownFrom records the scratchpad workspace a window was shown from.
+func (s *State) RecordShownFrom(windowID int, scratchpad string) {
+	if s.ShownFrom == nil {
+		s.ShownFrom = make(map[int]string)
+	}
+	s.ShownFrom[windowID] = scratchpad
+}
+
+// ForgetShownFrom forgets where a hidden window was shown from.
+func (s *State) ForgetShownFrom(windowID int) {
+	delete(s.ShownFrom, windowID)
+}
+
+// AddSticky records a shown window, once.
+func (s *State) AddSticky(windowID int) {
+	if !slices.Contains(s.Sticky, windowID) {
+		s.Sticky = append(s.Sticky, windowID)
+	}
+}
+
+// RemoveSticky forgets a window.
+func (s *State) RemoveSticky(windowID int) {
+	s.Sticky = slices.DeleteFunc(s.Sticky, func(id int) bool {
+		return id == windowID
+	})
+}
+
+// Store reads and writes the state file.
+type Store struct {
+	path string
+}
+
+// New returns the store of the current user and session.
+func New() *Store {
+	return NewAt(SessionFile("state"))
+}
+
+// NewAt returns a store kept at path.
+func NewAt(path string) *Store {
+	return &Store{path: path}
+}
+
+// SessionFile returns the location of a per-session file, in a directory
+// owned by the current user, e.g. `$TMPDIR/aerospace-scratchpad-501/<name>-<session>`.
+func SessionFile(name string) string {
+	session := os.Getenv(constants.EnvSecuritySessionID)
+	if session == "" {
+		session = "default"
+	}
+	// The session comes from the environment, keep it a single path element
+	session = strings.ReplaceAll(session, string(filepath.Separator), "_")
+
+	return filepath.Join(
+		os.TempDir(),
+		fmt.Sprintf("aerospace-scratchpad-%d", os.Getuid()),
+		name+"-"+session,
+	)
+}
+
+// Path returns where the state is stored.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load returns the stored state, empty when there is none or it is
+// unreadable.
+func (s *Store) Load() (State, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return State{}, nil
+		}
+		return State{}, fmt.Errorf("unable to read state: %w", err)
+	}
+
+	var state State
+	if err = json.Unmarshal(data, &state); err != nil {
+		// Unreadable states, e.g. from older versions, are reset
+		return State{}, nil
+	}
+
+	return state, nil
+}
+
+// Save replaces the stored state.
+func (s *Store) Save(state State) error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("unable to create state directory: %w", err)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	// Write then rename, so concurrent commands never read a partial state
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to write state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write state: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write state: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("unable to write state: %w", err)
+	}
+
+	return nil
+}
+
+// Update loads the state, applies update and saves it, holding the lock of
+// the state so concurrent commands never overwrite each other's updates.
+func (s *Store) Update(update func(state *State)) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	update(&state)
+	return s.Save(state)
+}
+
+// lock takes an exclusive lock on the lock file of the state. The state file
+// itself is replaced by each Save, so it cannot hold the lock.
+func (s *Store) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return nil, fmt.Errorf("unable to create state directory: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to lock state: %w", err)
+	}
+	if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unable to lock state: %w", err)
+	}
+
+	return func() {
+		// Closing the file releases the lock
+		file.Close()
+	}, nil
+}
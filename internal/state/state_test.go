@@ -0,0 +1,172 @@
+package state_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+)
+
+func TestStore(t *testing.T) {
+	t.Run("is empty until saved", func(t *testing.T) {
+		store := state.NewAt(filepath.Join(t.TempDir(), "user", "state"))
+
+		loaded, err := store.Load()
+		if err != nil || len(loaded.Sticky) != 0 {
+			t.Fatalf("expected an empty state, got %+v err=%v", loaded, err)
+		}
+	})
+
+	t.Run("keeps the sticky windows between runs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "user", "state")
+
+		err := state.NewAt(path).Update(func(s *state.State) {
+			s.AddSticky(1)
+			s.AddSticky(2)
+			s.AddSticky(1)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = state.NewAt(path).Update(func(s *state.State) {
+			s.RemoveSticky(1)
+			s.AddSticky(3)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		loaded, err := state.NewAt(path).Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(loaded.Sticky, []int{2, 3}) {
+			t.Errorf("expected sticky windows [2 3], got %v", loaded.Sticky)
+		}
+	})
+
+	t.Run("keeps the previous focus between runs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state")
+
+		err := state.NewAt(path).Update(func(s *state.State) {
+			s.SetPreviousFocus(1, 10)
+			s.SetPreviousFocus(2, 20)
+			s.ForgetPreviousFocus(1)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		loaded, err := state.NewAt(path).Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(loaded.PreviousFocus, map[int]int{2: 20}) {
+			t.Errorf("expected previous focus map[2:20], got %v", loaded.PreviousFocus)
+		}
+	})
+
+	t.Run("keeps where the windows were shown from between runs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state")
+
+		err := state.NewAt(path).Update(func(s *state.State) {
+			s.RecordShownFrom(1, ".scratchpad")
+			s.RecordShownFrom(2, ".scratchpad-pool-media")
+			s.ForgetShownFrom(1)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		loaded, err := state.NewAt(path).Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(loaded.ShownFrom, map[int]string{2: ".scratchpad-pool-media"}) {
+			t.Errorf("expected shown from map[2:.scratchpad-pool-media], got %v", loaded.ShownFrom)
+		}
+	})
+
+	t.Run("keeps the stashed windows between runs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state")
+		stashedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		shownAt := stashedAt.Add(time.Minute)
+
+		err := state.NewAt(path).Update(func(s *state.State) {
+			s.RecordStashed(1, "1", stashedAt)
+			s.RecordShown(1, shownAt)
+			s.RecordStashed(1, "", shownAt.Add(time.Minute))
+			s.RecordStashed(2, "2", stashedAt)
+			s.ForgetStash(2)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		loaded, err := state.NewAt(path).Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := map[int]state.StashRecord{1: {
+			Origin:    "1",
+			StashedAt: shownAt.Add(time.Minute),
+			ShownAt:   shownAt,
+		}}
+		if !reflect.DeepEqual(loaded.Stash, expected) {
+			t.Errorf("expected stashed windows %v, got %v", expected, loaded.Stash)
+		}
+	})
+
+	t.Run("keeps every concurrent update", func(t *testing.T) {
+		store := state.NewAt(filepath.Join(t.TempDir(), "user", "state"))
+
+		var wg sync.WaitGroup
+		for windowID := 1; windowID <= 20; windowID++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := store.Update(func(st *state.State) {
+					st.AddSticky(windowID)
+				}); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		loaded, err := store.Load()
+		if err != nil || len(loaded.Sticky) != 20 {
+			t.Fatalf("expected 20 sticky windows, got %v err=%v", loaded.Sticky, err)
+		}
+	})
+
+	t.Run("resets unreadable states", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state")
+		if err := os.WriteFile(path, []byte("sticky"), 0o600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		loaded, err := state.NewAt(path).Load()
+		if err != nil || len(loaded.Sticky) != 0 {
+			t.Fatalf("expected an empty state, got %+v err=%v", loaded, err)
+		}
+	})
+}
+
+func TestSessionFile(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	t.Setenv("SECURITYSESSIONID", "a/b")
+
+	path := state.SessionFile("state")
+	if filepath.Dir(filepath.Dir(path)) != tmp {
+		t.Errorf("expected a per-user directory in %s, got %s", tmp, path)
+	}
+	if filepath.Base(path) != "state-a_b" {
+		t.Errorf("expected the file to be named after the session, got %s", path)
+	}
+}
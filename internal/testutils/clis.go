@@ -10,49 +10,77 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 
 	"github.com/spf13/cobra"
 
 	aerospacecli "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace"
 )
 
+// CmdExecute runs the command and returns what it printed to stdout.
+//
+// When the command fails, the stdout produced before the failure is returned
+// alongside the error so partial results can be asserted as well.
+//
+// The duration_ms of the text and JSON events is reported as 0, it depends
+// on how fast the test runs.
 func CmdExecute(cmd *cobra.Command, args ...string) (string, error) {
 	cmd.SetArgs(args)
-	stdOut, err := CaptureStdOut(func() error {
+	out, err := CaptureStdOut(func() error {
 		return cmd.Execute()
 	})
-
-	if err != nil {
-		return "", err
-	}
-
-	return stdOut, nil
+	return durationPattern.ReplaceAllString(out, "${1}0"), err
 }
 
+//nolint:gochecknoglobals // compiled once, read-only
+var durationPattern = regexp.MustCompile(`(duration_ms=|"duration_ms":)\d+`)
+
+// CaptureStdOut runs f and captures everything written to stdout.
+//
+// The error is what f returned, or the stderr content when f succeeded but
+// still reported something to stderr.
+//
 //nolint:reassign // CaptureStdOut temporarily redirects standard streams for testing
 func CaptureStdOut(f func() error) (string, error) {
 	var buf bytes.Buffer
-	// Save original stdout
+	// Save original streams
 	old := os.Stdout
 	oldErr := os.Stderr
 	// Redirect stdout
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 	errFile, _ := os.CreateTemp("", "aerospace-scratchpad-stdout")
-	os.Stderr = errFile // Redirect stderr to the same pipe
+	defer os.Remove(errFile.Name())
+	os.Stderr = errFile
+
+	// Drain the pipe concurrently so large outputs never block the writer
+	copied := make(chan error, 1)
+	go func() {
+		_, copyErr := io.Copy(&buf, r)
+		copied <- copyErr
+	}()
 
 	// Run the function that prints to stdout
-	err := f()
-	if err != nil {
-		return "", err
+	runErr := f()
+
+	// Restore the original streams
+	os.Stdout = old
+	os.Stderr = oldErr
+
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to close writer: %w", err)
+	}
+	if err := <-copied; err != nil {
+		return "", fmt.Errorf("failed to read output: %w", err)
 	}
 
-	err = errFile.Close()
-	if err != nil {
+	if err := errFile.Close(); err != nil {
 		return "", fmt.Errorf("failed to close error file: %w", err)
 	}
-	// Restore stderr to original
-	os.Stderr = oldErr
+
+	if runErr != nil {
+		return buf.String(), runErr
+	}
 
 	// read the error file
 	errFileContent, err := os.ReadFile(errFile.Name())
@@ -60,21 +88,9 @@ func CaptureStdOut(f func() error) (string, error) {
 		return "", fmt.Errorf("failed to read error file: %w", err)
 	}
 	if len(errFileContent) > 0 {
-		return "", fmt.Errorf("%s", errFileContent)
+		return buf.String(), fmt.Errorf("%s", errFileContent)
 	}
 
-	// Close writer and restore stdout
-	err = w.Close()
-	if err != nil {
-		return "", fmt.Errorf("failed to close writer: %w", err)
-	}
-	os.Stdout = old
-
-	// Read output
-	_, err = io.Copy(&buf, r)
-	if err != nil {
-		return "", fmt.Errorf("failed to read output: %w", err)
-	}
 	return buf.String(), nil
 }
 
@@ -0,0 +1,26 @@
+package testutils
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// ReadFileEventually waits for a file written by a detached process, e.g. a
+// script hook, and returns its content. The test fails when it stays empty
+// past the timeout.
+func ReadFileEventually(t *testing.T, path string, timeout time.Duration) []byte {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		data, err := os.ReadFile(path)
+		if err == nil && len(data) > 0 {
+			return data
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s to be written, got %q err=%v", path, data, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
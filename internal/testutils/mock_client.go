@@ -3,6 +3,7 @@ package testutils
 import (
 	"encoding/json"
 	"strconv"
+	"strings"
 
 	"go.uber.org/mock/gomock"
 
@@ -99,6 +100,16 @@ func (m *MockAeroSpaceWM) CloseConnection() error {
 	return nil
 }
 
+// StubCommand answers a raw command, e.g. `list-workspaces --monitor mouse
+// --visible --json`, with the given stdout. For commands no service mock
+// covers.
+func (m *MockAeroSpaceWM) StubCommand(command string, stdout string) {
+	if m.routingConn.stubs == nil {
+		m.routingConn.stubs = map[string]string{}
+	}
+	m.routingConn.stubs[command] = stdout
+}
+
 // GetWindowsMock returns the underlying windows mock for setting expectations.
 func (m *MockAeroSpaceWM) GetWindowsMock() *windows_mock.MockWindowsService {
 	return m.windowsService
@@ -132,9 +143,14 @@ type routingConnection struct {
 	focusMock      *focus_mock.MockFocusService
 	layoutMock     *layout_mock.MockLayoutService
 	ctrl           *gomock.Controller
+	stubs          map[string]string
 }
 
 func (r *routingConnection) SendCommand(command string, args []string) (*client.Response, error) {
+	if stdout, ok := r.stubs[strings.Join(append([]string{command}, args...), " ")]; ok {
+		return &client.Response{ExitCode: 0, StdOut: stdout, StdErr: ""}, nil
+	}
+
 	// Route commands to the appropriate mock based on command name and args
 	switch command {
 	case "list-windows":
@@ -180,6 +196,9 @@ func (r *routingConnection) handleListWindows(args []string) (*client.Response,
 			if err != nil {
 				return &client.Response{ExitCode: 1, StdOut: "", StdErr: err.Error()}, err
 			}
+			if win == nil {
+				return &client.Response{ExitCode: 0, StdOut: "[]", StdErr: ""}, nil
+			}
 			jsonData, _ := json.Marshal([]windows.Window{*win})
 			return &client.Response{ExitCode: 0, StdOut: string(jsonData), StdErr: ""}, nil
 		}
@@ -239,6 +258,9 @@ func (r *routingConnection) handleListWorkspaces(args []string) (*client.Respons
 			if err != nil {
 				return &client.Response{ExitCode: 1, StdOut: "", StdErr: err.Error()}, err
 			}
+			if ws == nil {
+				return &client.Response{ExitCode: 0, StdOut: "[]", StdErr: ""}, nil
+			}
 			jsonData, _ := json.Marshal([]workspaces.Workspace{*ws})
 			return &client.Response{ExitCode: 0, StdOut: string(jsonData), StdErr: ""}, nil
 		}
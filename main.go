@@ -6,8 +6,8 @@ package main
 import (
 	"log"
 
-	aerospacecli "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace"
 	"github.com/cristianoliveira/aerospace-scratchpad/cmd"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
 	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
 )
 
@@ -25,10 +25,9 @@ func main() {
 	logger.SetDefaultLogger(defaultLogger)
 	defaultLogger.LogInfo("Executing Aerospace Scratchpad CLI")
 
-	aerospaceMarkClient, err := aerospacecli.NewClient()
-	if err != nil {
-		log.Printf("Error creating Aerospace client: %v", err)
-	}
+	// Connected by the commands that need AeroSpace, see cmd.RootCmd
+	client := aerospace.NewAeroSpaceClient(nil)
+	client.SetDialer(aerospace.DefaultDialer)
 
-	cmd.Execute(aerospaceMarkClient)
+	cmd.Execute(client)
 }
@@ -0,0 +1,83 @@
+package scratchpad
+
+import windowsipc "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+
+// EventSchemaVersion is the Event schema version, bumped when fields are
+// appended (version 1 predates SchemaVersion).
+const EventSchemaVersion = 2
+
+// Event describes the outcome of a scratchpad operation on a single window.
+//
+// Events are the stable output of this package and of the CLI `--output`
+// formats. Field names and JSON keys are part of the public contract, new
+// fields are only ever appended.
+//
+// Command is the operation that produced the event: move, show, summon,
+// next or list. The CLI also reports the hook and watch commands.
+//
+// Action is what happened to the window: to-scratchpad, to-workspace, focus
+// or list. Move reports moving the focus away from the focused window it
+// hides as "focus", on the window focused before it was shown, or as
+// "focus-next-tiling" without window. The watch command reports "closed"
+// for a stashed or shown window that was closed and "count", without
+// window, with the number of stashed windows in Message. A "timeout" action,
+// without window, reports that AeroSpace did not answer before the context
+// deadline. In atomic mode a "rollback" action reports a window sent back
+// from Workspace to its previous TargetWorkspace after a failure. With a
+// quota, Move reports an "evicted" action for each window taken out of the
+// scratchpad in Workspace, sent back to TargetWorkspace or closed as told by
+// Message.
+//
+// Result is one of:
+//   - ok: the action was applied
+//   - skipped: nothing to do, e.g. the window was already in place
+//   - none: nothing matched, the event carries no window
+//   - error: the action failed for this window, see Message
+//   - planned: dry-run only, the action would have been applied. Message
+//     holds its argument, e.g. the layout name for a "layout" action
+//
+// Pool is the scratchpad pool the window is stashed in or taken from, empty
+// for the shared and per-workspace scratchpads or when the event involves no
+// scratchpad.
+//
+// Monitor is the name of the monitor TargetWorkspace was resolved from, see
+// Target.Monitor, empty otherwise.
+//
+// WindowTitle, AppBundleID, WindowLayout and ParentLayout are the details of
+// the window as queried before the action, see WithWindow. ParentLayout is
+// the layout of the container holding the window.
+//
+// DurationMs is how long the operation took until the event was reported, in
+// milliseconds, for Move, Show, Summon, Next and the hooks. Zero otherwise.
+//
+// SchemaVersion is EventSchemaVersion, set when the CLI writes the event out.
+type Event struct {
+	Command         string `json:"command"`
+	Action          string `json:"action"`
+	WindowID        int    `json:"window_id"`
+	AppName         string `json:"app_name"`
+	Workspace       string `json:"workspace"`
+	TargetWorkspace string `json:"target_workspace"`
+	Result          string `json:"result"`
+	Message         string `json:"message"`
+	Pool            string `json:"pool"`
+	Monitor         string `json:"monitor"`
+	WindowTitle     string `json:"window_title"`
+	AppBundleID     string `json:"app_bundle_id"`
+	WindowLayout    string `json:"window_layout"`
+	ParentLayout    string `json:"parent_layout"`
+	DurationMs      int64  `json:"duration_ms"`
+	SchemaVersion   int    `json:"schema_version"`
+}
+
+// WithWindow returns the event with the ID, app and details of the window.
+// Workspace is left as set, it depends on the action.
+func (e Event) WithWindow(window windowsipc.Window) Event {
+	e.WindowID = window.WindowID
+	e.AppName = window.AppName
+	e.WindowTitle = window.WindowTitle
+	e.AppBundleID = window.AppBundleID
+	e.WindowLayout = window.WindowLayout
+	e.ParentLayout = window.WindowParentContainerLayout
+	return e
+}
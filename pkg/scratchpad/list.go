@@ -0,0 +1,140 @@
+package scratchpad
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	windowsipc "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+)
+
+// List returns one event per scratchpad window, sorted by app name.
+//
+// A scratchpad window is a window in a scratchpad workspace or a floating
+// window. The selector pattern, when given, is matched against the app name.
+func (s *Scratchpad) List(
+	ctx context.Context,
+	opts ListOptions,
+) (events []Event, err error) {
+	defer func() {
+		events = appendTimeoutEvent("list", events, err)
+	}()
+
+	if err = checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	aerospaceClient := s.client
+	logger := logger.GetDefaultLogger()
+	logger.LogDebug("LIST: start command", "opts", opts)
+
+	querier := aerospace.NewAerospaceQuerier(aerospaceClient)
+	scratchpadWindows, err := querier.GetScratchpadWindows(ctx)
+	if err != nil {
+		logger.LogError("LIST: unable to get scratchpad windows", "error", err)
+		return nil, err
+	}
+
+	logger.LogDebug("LIST: retrieved scratchpad windows", "count", len(scratchpadWindows))
+
+	filterFlags := opts.Filters
+	if pattern := strings.TrimSpace(opts.Pattern); pattern != "" {
+		filterFlags = append([]string{"app-name=" + pattern}, filterFlags...)
+	}
+
+	filteredWindows, err := applyFiltersToList(scratchpadWindows, filterFlags)
+	if err != nil {
+		return nil, err
+	}
+	sortWindowsByAppName(filteredWindows)
+	if opts.GroupByWorkspace {
+		groupWindowsByOwner(filteredWindows)
+	}
+
+	return listEvents(filteredWindows), nil
+}
+
+func applyFiltersToList(
+	scratchpadWindows []windowsipc.Window,
+	filterFlags []string,
+) ([]windowsipc.Window, error) {
+	if len(filterFlags) == 0 {
+		return scratchpadWindows, nil
+	}
+
+	filters, err := aerospace.ParseFilters(filterFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredWindows []windowsipc.Window
+	for _, window := range scratchpadWindows {
+		matches, applyErr := aerospace.ApplyFilters(window, filters)
+		if applyErr != nil {
+			return nil, applyErr
+		}
+		if matches {
+			filteredWindows = append(filteredWindows, window)
+		}
+	}
+
+	return filteredWindows, nil
+}
+
+func sortWindowsByAppName(windows []windowsipc.Window) {
+	sort.Slice(windows, func(i, j int) bool {
+		// Sort by app name first
+		if windows[i].AppName != windows[j].AppName {
+			return windows[i].AppName < windows[j].AppName
+		}
+		// If app names are equal, sort by window ID for stable ordering
+		return windows[i].WindowID < windows[j].WindowID
+	})
+}
+
+// groupWindowsByOwner stable sorts the windows by owning workspace, keeping
+// the previous order within each group.
+func groupWindowsByOwner(windows []windowsipc.Window) {
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windowOwner(windows[i]) < windowOwner(windows[j])
+	})
+}
+
+// windowOwner returns the workspace a scratchpad window belongs to, empty
+// for the shared scratchpad and the pools.
+func windowOwner(window windowsipc.Window) string {
+	switch kind, scope := aerospace.ScratchpadScope(window.Workspace); kind {
+	case aerospace.ScratchpadKindWorkspace:
+		return scope
+	case aerospace.ScratchpadKindShared, aerospace.ScratchpadKindPool:
+		return ""
+	default:
+		return window.Workspace
+	}
+}
+
+func listEvents(windows []windowsipc.Window) []Event {
+	if len(windows) == 0 {
+		return []Event{{
+			Command:   "list",
+			Action:    "list",
+			Result:    "none",
+			Message:   "no scratchpad windows found",
+			Workspace: "",
+		}}
+	}
+
+	events := make([]Event, 0, len(windows))
+	for _, window := range windows {
+		events = append(events, Event{
+			Command:   "list",
+			Action:    "list",
+			Workspace: window.Workspace,
+			Result:    "ok",
+		}.WithWindow(window))
+	}
+
+	return setPools(events)
+}
@@ -0,0 +1,414 @@
+package scratchpad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	windowsipc "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+)
+
+// Move moves the selected windows to the scratchpad, or to the pool of the
+// selector when set.
+//
+// Without a pattern it moves the focused window, or every window of the same
+// app when AllMatching is set.
+//
+// Returns the events for every processed window. When some windows fail to
+// move the remaining ones are still processed and the errors are joined,
+// unless running in atomic mode where the first failure rolls back the
+// windows moved so far.
+//
+//nolint:funlen,gocognit
+func (s *Scratchpad) Move(
+	ctx context.Context,
+	opts MoveOptions,
+) (events []Event, err error) {
+	start := time.Now()
+	defer func() {
+		s.updatePreviousFocus(0, events)
+		events = s.finish("move", start, events, err)
+	}()
+
+	if err = checkContext(ctx); err != nil {
+		return nil, err
+	}
+	if err = ValidatePool(opts.Pool); err != nil {
+		return nil, err
+	}
+
+	aerospaceClient := s.client
+	logger := logger.GetDefaultLogger()
+	logger.LogDebug("MOVE: start command", "opts", opts)
+
+	var windowNamePattern string
+	focusedWindowID := -1
+
+	// Skip pattern logic when --all-floating is used
+	if !opts.AllFloating {
+		windowNamePattern, focusedWindowID, err = getWindowPattern(
+			ctx,
+			opts.Pattern,
+			aerospaceClient,
+			logger,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Query windows matching pattern and filters
+	querier := aerospace.NewAerospaceQuerier(aerospaceClient)
+	mover := s.newMover(opts.Pool)
+
+	var windows []windowsipc.Window
+	if opts.AllFloating {
+		// Get all floating windows when --all-floating is set
+		logger.LogDebug("MOVE: using --all-floating flag, getting all floating windows")
+		windows, err = querier.GetAllFloatingWindows(ctx)
+		if err != nil {
+			logger.LogError(
+				"MOVE: error retrieving floating windows",
+				"error", err,
+			)
+			return nil, err
+		}
+	} else {
+		// Normal pattern-based filtering
+		windows, err = querier.GetFilteredWindows(
+			ctx,
+			windowNamePattern,
+			opts.Filters,
+		)
+		if err != nil {
+			logger.LogError(
+				"MOVE: error retrieving filtered windows",
+				"error", err,
+				"pattern", windowNamePattern,
+				"filterFlags", opts.Filters,
+			)
+			return nil, err
+		}
+	}
+
+	logger.LogDebug(
+		"MOVE: retrieved filtered windows",
+		"windows", windows,
+		"filterFlags", opts.Filters,
+	)
+
+	// When using --all-floating, skip the focused window check
+	if opts.AllFloating && len(windows) == 0 {
+		return []Event{{
+			Command:   "move",
+			Action:    "to-scratchpad",
+			Result:    "none",
+			Message:   "no floating windows found",
+			Workspace: "",
+		}}, nil
+	}
+
+	var toMove []windowsipc.Window
+	for _, window := range windows {
+		// Skip non-focused windows unless the --all-matching or --all-floating flag is provided
+		if !opts.AllFloating && focusedWindowID != -1 &&
+			window.WindowID != focusedWindowID &&
+			!opts.AllMatching {
+			logger.LogDebug(
+				"MOVE: skipping window, not focused and --all-matching flag not provided",
+				"window", window,
+				"focusedWindowId", focusedWindowID,
+			)
+			continue
+		}
+		toMove = append(toMove, window)
+	}
+
+	if s.hidesFocusedWindow(ctx, &mover, toMove, focusedWindowID) {
+		logger.LogDebug(
+			"MOVE: focused window to hide, focusing the previous or next tiling window",
+		)
+		events = append(events, s.refocusAfterHide(ctx, "move", toMove))
+	}
+
+	var moveErrs []error
+	for _, window := range toMove {
+		if err = checkContext(ctx); err != nil {
+			return events, errors.Join(append(moveErrs, err)...)
+		}
+
+		moveErr := mover.MoveWindowToScratchpad(ctx, window)
+		if moveErr != nil {
+			if errors.Is(moveErr, aerospace.ErrAlreadyInWorkspace) {
+				events = append(events, Event{
+					Command:         "move",
+					Action:          "to-scratchpad",
+					Workspace:       window.Workspace,
+					TargetWorkspace: mover.ScratchpadFor(window),
+					Result:          "skipped",
+					Message:         "already in scratchpad",
+				}.WithWindow(window))
+				continue
+			}
+
+			logger.LogError(
+				"MOVE: error moving window to scratchpad",
+				"window", window,
+				"error", moveErr,
+			)
+			if s.atomic {
+				events = append(events, s.rollback(ctx, &mover, Event{
+					Command:         "move",
+					Action:          "to-scratchpad",
+					Workspace:       window.Workspace,
+					TargetWorkspace: mover.ScratchpadFor(window),
+					Result:          "error",
+					Message:         moveErr.Error(),
+				}.WithWindow(window))...)
+				return events, moveErr
+			}
+			// Continue with remaining windows instead of returning
+			moveErrs = append(moveErrs, moveErr)
+			continue
+		}
+
+		events = append(events, Event{
+			Command:         "move",
+			Action:          "to-scratchpad",
+			Workspace:       window.Workspace,
+			TargetWorkspace: mover.ScratchpadFor(window),
+			Result:          "ok",
+		}.WithWindow(window))
+	}
+
+	evictions, evictErr := s.evict(ctx, events)
+	events = append(events, evictions...)
+
+	return events, errors.Join(append(moveErrs, evictErr)...)
+}
+
+// evict applies the quota to the scratchpads the events moved windows to.
+// The windows just moved are never evicted. An eviction failing is reported
+// and the others are still evicted.
+func (s *Scratchpad) evict(ctx context.Context, events []Event) ([]Event, error) {
+	if !s.quota.Enabled() {
+		return nil, nil
+	}
+
+	logger := logger.GetDefaultLogger()
+
+	// The windows just moved by scratchpad, in dry-run they are not there yet
+	moved := make(map[string][]windowsipc.Window)
+	var scratchpads []string
+	for _, event := range events {
+		if event.Action != "to-scratchpad" || event.Result != "ok" {
+			continue
+		}
+		if _, ok := moved[event.TargetWorkspace]; !ok {
+			scratchpads = append(scratchpads, event.TargetWorkspace)
+		}
+		moved[event.TargetWorkspace] = append(moved[event.TargetWorkspace], windowsipc.Window{
+			WindowID:  event.WindowID,
+			AppName:   event.AppName,
+			Workspace: event.Workspace,
+		})
+	}
+	if len(scratchpads) == 0 {
+		return nil, nil
+	}
+
+	stash := loadStash()
+	var evictions []Event
+	var evictErrs []error
+	for _, scratchpad := range scratchpads {
+		stashed, err := s.client.GetAllWindowsByWorkspace(ctx, scratchpad)
+		if err != nil {
+			logger.LogError("MOVE: unable to get scratchpad windows", "scratchpad", scratchpad, "error", err)
+			evictErrs = append(evictErrs, err)
+			continue
+		}
+
+		times := make(map[int]aerospace.StashTimes, len(stashed))
+		for _, window := range stashed {
+			record := stash[window.WindowID]
+			times[window.WindowID] = aerospace.StashTimes{
+				StashedAt: record.StashedAt,
+				ShownAt:   record.ShownAt,
+			}
+		}
+		var kept []int
+		for _, window := range moved[scratchpad] {
+			kept = append(kept, window.WindowID)
+			if !slices.ContainsFunc(stashed, func(stashedWindow windowsipc.Window) bool {
+				return stashedWindow.WindowID == window.WindowID
+			}) {
+				stashed = append(stashed, window)
+			}
+		}
+
+		evicted := aerospace.PlanEviction(s.quota, stashed, times, kept)
+		logger.LogDebug("MOVE: evicting windows", "scratchpad", scratchpad, "windows", evicted)
+		for _, window := range evicted {
+			// Windows stashed before the quota was set have no known origin,
+			// they go where the windows just moved came from
+			origin := stash[window.WindowID].Origin
+			if origin == "" {
+				origin = s.fallbackOrigin(ctx, moved[scratchpad])
+			}
+
+			event, evictErr := s.evictWindow(ctx, window, origin)
+			if evictErr != nil {
+				evictErrs = append(evictErrs, evictErr)
+			}
+			evictions = append(evictions, event)
+		}
+	}
+
+	return evictions, errors.Join(evictErrs...)
+}
+
+// fallbackOrigin returns the workspace an evicted window without a known
+// origin goes back to: the first one the moved windows came from that is not
+// a scratchpad, or else the focused workspace.
+func (s *Scratchpad) fallbackOrigin(ctx context.Context, moved []windowsipc.Window) string {
+	for _, window := range moved {
+		if !aerospace.IsScratchpadWorkspace(window.Workspace) {
+			return window.Workspace
+		}
+	}
+
+	workspace, err := s.client.GetFocusedWorkspace(ctx)
+	if err != nil || workspace == nil {
+		logger.GetDefaultLogger().LogError("MOVE: unable to get the focused workspace", "error", err)
+		return ""
+	}
+	return workspace.Workspace
+}
+
+// evictWindow applies the quota action to an evicted window: closing it, or
+// sending it back to its origin workspace, tiled for the tile action.
+func (s *Scratchpad) evictWindow(
+	ctx context.Context,
+	window windowsipc.Window,
+	origin string,
+) (Event, error) {
+	action := s.quota.Action
+	if action == "" {
+		action = aerospace.EvictReturn
+	}
+
+	event := Event{
+		Command:         "move",
+		Action:          "evicted",
+		Workspace:       window.Workspace,
+		TargetWorkspace: origin,
+		Result:          "ok",
+		Message:         action,
+	}.WithWindow(window)
+
+	var err error
+	switch {
+	case action == aerospace.EvictClose:
+		event.TargetWorkspace = ""
+		err = s.client.CloseWindow(ctx, window.WindowID)
+	case origin == "":
+		err = errors.New("no workspace to return the window to")
+	case action == aerospace.EvictTile:
+		err = s.client.MoveWindowToWorkspace(ctx, window.WindowID, origin)
+		if err == nil {
+			err = s.client.SetLayout(ctx, window.WindowID, "tiling")
+		}
+	default:
+		err = s.client.MoveWindowToWorkspace(ctx, window.WindowID, origin)
+	}
+	if err != nil {
+		logger.GetDefaultLogger().LogError("MOVE: unable to evict window", "window", window, "error", err)
+		event.Result = "error"
+		event.Message = err.Error()
+		return event, fmt.Errorf("unable to evict window '%+v': %w", window, err)
+	}
+
+	return event, nil
+}
+
+// loadStash returns what is known about the scratchpad windows, by window.
+func loadStash() map[int]state.StashRecord {
+	st, err := state.New().Load()
+	if err != nil {
+		logger.GetDefaultLogger().LogError("STATE: unable to load stashed windows", "error", err)
+	}
+	return st.Stash
+}
+
+// hidesFocusedWindow returns whether the focused window is one of the windows
+// about to be moved to their scratchpad. The focused window is queried when
+// not known yet, failing to do so leaves the focus untouched.
+func (s *Scratchpad) hidesFocusedWindow(
+	ctx context.Context,
+	mover *aerospace.MoverAeroSpace,
+	windows []windowsipc.Window,
+	focusedWindowID int,
+) bool {
+	if len(windows) == 0 {
+		return false
+	}
+
+	if focusedWindowID == -1 {
+		focusedWindow, err := s.client.GetFocusedWindow(ctx)
+		if err != nil || focusedWindow == nil {
+			logger.GetDefaultLogger().LogDebug(
+				"MOVE: no focused window to move away from",
+				"error", err,
+			)
+			return false
+		}
+		focusedWindowID = focusedWindow.WindowID
+	}
+
+	return slices.ContainsFunc(windows, func(window windowsipc.Window) bool {
+		return window.WindowID == focusedWindowID &&
+			window.Workspace != mover.ScratchpadFor(window)
+	})
+}
+
+// getWindowPattern determines the window pattern and focused window ID from
+// the given pattern. Returns pattern, focusedWindowID, and error.
+func getWindowPattern(
+	ctx context.Context,
+	pattern string,
+	aerospaceClient *aerospace.AeroSpaceClient,
+	log logger.Logger,
+) (string, int, error) {
+	windowNamePattern := strings.TrimSpace(pattern)
+	focusedWindowID := -1
+
+	if windowNamePattern == "" {
+		focusedWindow, err := aerospaceClient.GetFocusedWindow(ctx)
+		log.LogDebug(
+			"MOVE: retrieving focused window",
+			"focusedWindow", focusedWindow,
+			"error", err,
+		)
+		if err != nil {
+			return "", -1, fmt.Errorf("unable to get focused window: %w", err)
+		}
+		if focusedWindow == nil {
+			return "", -1, errors.New("no focused window found")
+		}
+		focusedWindowID = focusedWindow.WindowID
+		windowNamePattern = fmt.Sprintf("^%s$", focusedWindow.AppName)
+		log.LogDebug(
+			"MOVE: using focused window app name as pattern",
+			"windowNamePattern", windowNamePattern,
+			"focusedWindowId", focusedWindowID,
+		)
+	}
+
+	return windowNamePattern, focusedWindowID, nil
+}
@@ -0,0 +1,84 @@
+package scratchpad
+
+import (
+	"context"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+)
+
+// Next brings the next scratchpad window to the focused workspace.
+//
+// With a pool it only takes windows from that pool. In per-workspace mode it
+// takes the windows stashed from the focused workspace first, then the
+// shared scratchpad ones. With NextOptions.Target the window is brought to
+// another workspace.
+func (s *Scratchpad) Next(
+	ctx context.Context,
+	opts NextOptions,
+) (events []Event, err error) {
+	start := time.Now()
+	// Resolved below, the monitor is reported by the events
+	var targetWorkspace *workspaces.Workspace
+	var monitor string
+	defer func() {
+		events = setMonitor(s.finish("next", start, events, err), targetWorkspace, monitor)
+	}()
+
+	if err = checkContext(ctx); err != nil {
+		return nil, err
+	}
+	if err = ValidatePool(opts.Pool); err != nil {
+		return nil, err
+	}
+	if err = opts.Target.Validate(); err != nil {
+		return nil, err
+	}
+
+	targetWorkspace, monitor, err = s.targetWorkspace(ctx, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+	setFocus := s.focusesTarget(ctx, opts.Target)
+
+	querier := aerospace.NewAerospaceQuerier(s.client)
+	mover := s.newMover(opts.Pool)
+
+	scratchpads := []string{constants.DefaultScratchpadWorkspaceName}
+	switch {
+	case opts.Pool != "":
+		scratchpads = []string{aerospace.PoolWorkspace(opts.Pool)}
+	case s.perWorkspace:
+		// Windows stashed from the target workspace come first
+		scratchpads = append(
+			[]string{aerospace.WorkspaceScratchpad(targetWorkspace.Workspace)},
+			scratchpads...,
+		)
+	}
+
+	window, err := querier.GetNextScratchpadWindowIn(ctx, scratchpads...)
+	if err != nil {
+		return nil, err
+	}
+
+	if moveErr := mover.MoveWindowToWorkspace(
+		ctx,
+		window,
+		targetWorkspace,
+		setFocus,
+	); moveErr != nil {
+		return nil, moveErr
+	}
+
+	event := Event{
+		Command:         "next",
+		Action:          "to-workspace",
+		Workspace:       window.Workspace,
+		TargetWorkspace: targetWorkspace.Workspace,
+		Result:          "ok",
+	}.WithWindow(*window)
+
+	return []Event{event}, nil
+}
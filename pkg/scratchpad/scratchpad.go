@@ -0,0 +1,462 @@
+// Package scratchpad exposes the aerospace-scratchpad operations as a Go API.
+//
+// It is what the `aerospace-scratchpad` CLI uses under the hood, so embedding
+// it gives the exact same behavior without spawning the binary.
+//
+// Usage:
+//
+//	client, err := aerospace.NewClient() // github.com/cristianoliveira/aerospace-ipc/pkg/aerospace
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer client.CloseConnection()
+//
+//	sp := scratchpad.New(client)
+//	events, err := sp.Show(ctx, scratchpad.ShowOptions{
+//	    Selector: scratchpad.Selector{Pattern: "^Spotify$"},
+//	})
+//
+// Every operation honors the context, use context.WithTimeout to make sure a
+// hanging AeroSpace never blocks the caller. When the deadline is hit the
+// operation returns ErrTimeout along with a "timeout" event.
+package scratchpad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/focus"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/layout"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/client"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+)
+
+// ErrTimeout is returned when AeroSpace does not answer before the context
+// deadline.
+var ErrTimeout = aerospace.ErrTimeout
+
+// Client is the AeroSpace client the scratchpad operates on.
+// It is satisfied by *aerospace.AeroSpaceWM from aerospace-ipc.
+type Client interface {
+	Windows() *windows.Service
+	Workspaces() *workspaces.Service
+	Focus() *focus.Service
+	Layout() *layout.Service
+	Connection() client.AeroSpaceConnection
+}
+
+// Options configures a Scratchpad.
+type Options struct {
+	// DryRun reports the operations without moving any window
+	DryRun bool
+	// Atomic reverts the windows already moved by Move, Show or Summon when
+	// a later move fails, reporting a "rollback" event for each of them
+	Atomic bool
+	// PerWorkspace stashes windows in a scratchpad owned by the workspace
+	// they come from, e.g. `.scratchpad-ws-ws1`. Show and Next prefer the
+	// windows stashed from the focused workspace.
+	PerWorkspace bool
+	// Sticky records the windows Show brings to the focused workspace in the
+	// session state, so `aerospace-scratchpad hook follow` carries them
+	// along when the focused workspace changes, with the window focused
+	// before them to restore when they are hidden and the scratchpad they
+	// came from, where `hook focus-changed` hides them back. Off by default,
+	// no state is written then except for the Quota.
+	Sticky bool
+	// Quota bounds the windows of each scratchpad, Move evicts the excess
+	// windows. The zero value disables it.
+	Quota EvictionPolicy
+}
+
+// Selector selects the windows an operation applies to.
+type Selector struct {
+	// Pattern is a regex matched against the window app name
+	Pattern string
+	// Filters are extra `property=regex` filters, e.g. `window-title=^foo`.
+	// Supported properties: app-name, window-title, app-bundle-id,
+	// window-id, workspace and window-layout.
+	Filters []string
+	// Pool restricts the operation to a named scratchpad pool, stashed in
+	// its own hidden workspace, e.g. `.scratchpad-pool-media` for `media`. Empty
+	// uses the shared scratchpad.
+	Pool string
+}
+
+// NextOptions configures Next.
+type NextOptions struct {
+	// Pool takes the next window from a named pool, see Selector.Pool
+	Pool string
+	// Target is the workspace the window is brought to
+	Target Target
+}
+
+// MoveOptions configures Move.
+type MoveOptions struct {
+	Selector
+
+	// AllMatching moves every window matching the selector instead of only
+	// the focused one
+	AllMatching bool
+	// AllFloating moves every floating window and ignores the selector pattern
+	AllFloating bool
+}
+
+// TogglePolicy selects how Show brings, focuses and hides the windows, e.g.
+// TogglePolicy{Bring: "one", AfterHide: "previous"}. Empty fields keep the
+// default behavior.
+type TogglePolicy = aerospace.TogglePolicy
+
+// EvictionPolicy selects how many windows a scratchpad holds and what
+// happens to the excess ones, e.g. EvictionPolicy{MaxWindows: 5, Action:
+// "close"}. Empty fields keep the default behavior.
+type EvictionPolicy = aerospace.EvictionPolicy
+
+// ShowOptions configures Show.
+type ShowOptions struct {
+	Selector
+
+	// Target is the workspace the windows are brought to, it stands for the
+	// focused workspace in the toggle
+	Target Target
+	// Policy selects the toggle semantics
+	Policy TogglePolicy
+}
+
+// SummonOptions configures Summon.
+type SummonOptions struct {
+	Selector
+
+	// Target is the workspace the windows are brought to
+	Target Target
+	// Toggle hides the matched windows instead when they are all in the
+	// focused workspace and one of them is focused, like Show does
+	Toggle bool
+}
+
+// ListOptions configures List.
+type ListOptions struct {
+	Selector
+
+	// GroupByWorkspace sorts the windows by owning workspace first: the
+	// workspace of a per-workspace scratchpad, or the workspace of a floating
+	// window. Windows of the shared scratchpad and the pools come first.
+	GroupByWorkspace bool
+}
+
+// Scratchpad runs scratchpad operations against AeroSpace.
+type Scratchpad struct {
+	client       *aerospace.AeroSpaceClient
+	atomic       bool
+	perWorkspace bool
+	sticky       bool
+	quota        EvictionPolicy
+}
+
+// New creates a Scratchpad for the given AeroSpace client.
+func New(client Client) *Scratchpad {
+	// Library users do not configure logging, keep it silent by default
+	logger.EnsureDefaultLogger(&logger.EmptyLogger{})
+
+	return &Scratchpad{
+		client: aerospace.NewAeroSpaceClient(client),
+	}
+}
+
+// SetOptions updates the options used by the following operations.
+func (s *Scratchpad) SetOptions(opts Options) {
+	s.client.SetOptions(aerospace.ClientOpts{
+		DryRun: opts.DryRun,
+	})
+	s.atomic = opts.Atomic
+	s.perWorkspace = opts.PerWorkspace
+	s.sticky = opts.Sticky
+	s.quota = opts.Quota
+}
+
+// newMover creates a mover stashing windows in the given pool, or in the
+// scratchpad selected by the options when the pool is empty.
+func (s *Scratchpad) newMover(pool string) aerospace.MoverAeroSpace {
+	mover := aerospace.NewAeroSpaceMover(s.client)
+	switch {
+	case pool != "":
+		mover.SetScratchpadResolver(aerospace.PoolScratchpad(pool))
+	case s.perWorkspace:
+		mover.SetScratchpadResolver(aerospace.PerWorkspaceScratchpad)
+	}
+	return mover
+}
+
+// ValidatePool makes sure the pool name is usable as part of a workspace
+// name.
+func ValidatePool(pool string) error {
+	if pool == "" || poolNamePattern.MatchString(pool) {
+		return nil
+	}
+	return fmt.Errorf(
+		"invalid pool '%s': only letters, digits, '.', '_' and '-' are allowed",
+		pool,
+	)
+}
+
+//nolint:gochecknoglobals // compiled once, read-only
+var poolNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// setPools fills the pool of the events from the scratchpad workspace they
+// move the window to, or take it from.
+func setPools(events []Event) []Event {
+	for i, event := range events {
+		if event.Pool != "" {
+			continue
+		}
+		if aerospace.IsScratchpadWorkspace(event.TargetWorkspace) {
+			events[i].Pool = aerospace.PoolOf(event.TargetWorkspace)
+			continue
+		}
+		events[i].Pool = aerospace.PoolOf(event.Workspace)
+	}
+	return events
+}
+
+// rollbackTimeout bounds the rollback of an atomic operation, which runs
+// even when the operation context is already done.
+const rollbackTimeout = 2 * time.Second
+
+// rollback reverts the moves completed by the mover after a failure in
+// atomic mode. It returns the failure event followed by one "rollback" event
+// per reverted window.
+func (s *Scratchpad) rollback(
+	ctx context.Context,
+	mover *aerospace.MoverAeroSpace,
+	failure Event,
+) []Event {
+	events := []Event{failure}
+
+	// The failure may be a timeout, give the rollback its own deadline
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	logger.GetDefaultLogger().LogInfo(
+		"ROLLBACK: reverting moved windows",
+		"command", failure.Command,
+		"steps", len(mover.Steps()),
+	)
+	for _, step := range mover.Rollback(ctx) {
+		event := Event{
+			Command:         failure.Command,
+			Action:          "rollback",
+			Workspace:       step.TargetWorkspace,
+			TargetWorkspace: step.Window.Workspace,
+			Result:          "ok",
+		}.WithWindow(step.Window)
+		if step.Err != nil {
+			event.Result = "error"
+			event.Message = step.Err.Error()
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// checkContext returns the context error, if any, so operations stop before
+// sending further commands to AeroSpace.
+func checkContext(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// finish completes the events of a mutating operation started at start, see
+// FinishEvents.
+func (s *Scratchpad) finish(command string, start time.Time, events []Event, err error) []Event {
+	s.updateStash(events)
+	s.updateShownFrom(events)
+	return FinishEvents(s.client, command, start, events, err)
+}
+
+// FinishEvents completes the events of a command started at start that
+// drove client itself, e.g. the hooks. In dry-run the planned operations
+// replace the events of the applied actions, and a timeout is reported as
+// its own event. Every event gets the duration of the command.
+func FinishEvents(
+	client Client,
+	command string,
+	start time.Time,
+	events []Event,
+	err error,
+) []Event {
+	if wrapper, ok := client.(*aerospace.AeroSpaceClient); ok && wrapper.IsDryRun() {
+		events = planEvents(command, wrapper.TakePlan(), events)
+	}
+
+	events = appendTimeoutEvent(command, setPools(events), err)
+	duration := time.Since(start).Milliseconds()
+	for i := range events {
+		events[i].DurationMs = duration
+	}
+	return events
+}
+
+// updateSticky records the windows shown by the events as sticky and forgets
+// the hidden ones. Failing to do so only stops the windows from following the
+// focused workspace, it never fails the operation.
+func (s *Scratchpad) updateSticky(events []Event) {
+	if !s.sticky || s.client.IsDryRun() {
+		return
+	}
+
+	err := state.New().Update(func(st *state.State) {
+		for _, event := range events {
+			if event.Result != "ok" {
+				continue
+			}
+			switch event.Action {
+			case "to-workspace":
+				st.AddSticky(event.WindowID)
+			case "to-scratchpad":
+				st.RemoveSticky(event.WindowID)
+			}
+		}
+	})
+	if err != nil {
+		logger.GetDefaultLogger().LogError("STATE: unable to update sticky windows", "error", err)
+	}
+}
+
+// updateShownFrom records the scratchpad workspace of the windows brought out
+// of one by the events and forgets the hidden ones, in sticky mode only.
+// Failing to do so only stops `hook focus-changed` from hiding them, it never
+// fails the operation.
+func (s *Scratchpad) updateShownFrom(events []Event) {
+	if !s.sticky || s.client.IsDryRun() || !slices.ContainsFunc(events, func(event Event) bool {
+		return event.Result == "ok" &&
+			(event.Action == "to-scratchpad" ||
+				event.Action == "to-workspace" && aerospace.IsScratchpadWorkspace(event.Workspace))
+	}) {
+		return
+	}
+
+	err := state.New().Update(func(st *state.State) {
+		for _, event := range events {
+			if event.Result != "ok" {
+				continue
+			}
+			switch event.Action {
+			case "to-workspace":
+				// Summoned from a regular workspace, the known origin is kept
+				if aerospace.IsScratchpadWorkspace(event.Workspace) {
+					st.RecordShownFrom(event.WindowID, event.Workspace)
+				}
+			case "to-scratchpad":
+				st.ForgetShownFrom(event.WindowID)
+			}
+		}
+	})
+	if err != nil {
+		logger.GetDefaultLogger().LogError("STATE: unable to update shown windows", "error", err)
+	}
+}
+
+// updateStash records when the windows of the events were stashed and shown,
+// and forgets the evicted ones, for the eviction order of the quota. Failing
+// to do so only makes the order less accurate, it never fails the operation.
+func (s *Scratchpad) updateStash(events []Event) {
+	if !s.quota.Enabled() || s.client.IsDryRun() {
+		return
+	}
+
+	now := time.Now()
+	err := state.New().Update(func(st *state.State) {
+		for _, event := range events {
+			if event.Result != "ok" {
+				continue
+			}
+			switch event.Action {
+			case "to-scratchpad":
+				origin := event.Workspace
+				if aerospace.IsScratchpadWorkspace(origin) {
+					origin = ""
+				}
+				st.RecordStashed(event.WindowID, origin, now)
+			case "to-workspace":
+				st.RecordShown(event.WindowID, now)
+			case "evicted":
+				st.ForgetStash(event.WindowID)
+			}
+		}
+	})
+	if err != nil {
+		logger.GetDefaultLogger().LogError("STATE: unable to update stashed windows", "error", err)
+	}
+}
+
+// planEvents turns the planned operations into "planned" events, taking the
+// window details from the events of the same window. Events that do not
+// report an applied action, e.g. skipped windows, are kept after the plan.
+func planEvents(command string, plan aerospace.Plan, events []Event) []Event {
+	windowEvents := make(map[int]Event, len(events))
+	var kept []Event
+	for _, event := range events {
+		if event.WindowID != 0 {
+			windowEvents[event.WindowID] = event
+		}
+		if event.Result != "ok" {
+			kept = append(kept, event)
+		}
+	}
+
+	planned := make([]Event, 0, len(plan)+len(kept))
+	for _, operation := range plan {
+		window := windowEvents[operation.WindowID]
+
+		action := operation.Action
+		if action == aerospace.PlanActionToWorkspace &&
+			aerospace.IsScratchpadWorkspace(operation.Workspace) {
+			action = "to-scratchpad"
+		}
+
+		planned = append(planned, Event{
+			Command:         command,
+			Action:          action,
+			WindowID:        operation.WindowID,
+			AppName:         window.AppName,
+			Workspace:       window.Workspace,
+			TargetWorkspace: operation.Workspace,
+			Result:          "planned",
+			Message:         operation.Detail,
+			WindowTitle:     window.WindowTitle,
+			AppBundleID:     window.AppBundleID,
+			WindowLayout:    window.WindowLayout,
+			ParentLayout:    window.ParentLayout,
+		})
+	}
+
+	return append(planned, kept...)
+}
+
+// appendTimeoutEvent reports a timed out operation as an event, so the
+// consumers of the output see why the operation stopped.
+func appendTimeoutEvent(command string, events []Event, err error) []Event {
+	if !errors.Is(err, ErrTimeout) {
+		return events
+	}
+
+	return append(events, Event{
+		Command: command,
+		Action:  "timeout",
+		Result:  "error",
+		Message: err.Error(),
+	})
+}
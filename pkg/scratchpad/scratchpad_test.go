@@ -0,0 +1,524 @@
+package scratchpad_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/constants"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/testutils"
+	"github.com/cristianoliveira/aerospace-scratchpad/pkg/scratchpad"
+)
+
+func TestScratchpad(t *testing.T) {
+	t.Run("Next returns events without printing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		scratchpadWindow := windows.Window{
+			AppName:   "Scratchpad Window",
+			WindowID:  9999,
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+		}
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		gomock.InOrder(
+			mockClient.GetWorkspacesMock().EXPECT().
+				GetFocusedWorkspace().
+				Return(&workspaces.Workspace{Workspace: "ws1"}, nil).
+				Times(1),
+			mockClient.GetWindowsMock().EXPECT().
+				GetAllWindowsByWorkspace(constants.DefaultScratchpadWorkspaceName).
+				Return([]windows.Window{scratchpadWindow}, nil).
+				Times(1),
+			mockClient.GetWorkspacesMock().EXPECT().
+				MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(1),
+			mockClient.GetFocusMock().EXPECT().
+				SetFocusByWindowID(scratchpadWindow.WindowID).
+				Return(nil).
+				Times(1),
+		)
+
+		var events []scratchpad.Event
+		out, err := testutils.CaptureStdOut(func() error {
+			var runErr error
+			events, runErr = scratchpad.New(mockClient).Next(context.Background(), scratchpad.NextOptions{})
+			return runErr
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if out != "" {
+			t.Errorf("expected nothing printed, got %s", out)
+		}
+
+		expected := scratchpad.Event{
+			Command:         "next",
+			Action:          "to-workspace",
+			WindowID:        scratchpadWindow.WindowID,
+			AppName:         scratchpadWindow.AppName,
+			Workspace:       constants.DefaultScratchpadWorkspaceName,
+			TargetWorkspace: "ws1",
+			Result:          "ok",
+		}
+		if len(events) != 1 || events[0] != expected {
+			t.Fatalf("expected %+v, got %+v", expected, events)
+		}
+	})
+
+	t.Run("List matches the selector pattern against the app name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		floatingWindows := []windows.Window{
+			{AppName: "Spotify", WindowID: 1, WindowLayout: "floating", Workspace: "ws1"},
+			{AppName: "Terminal", WindowID: 2, WindowLayout: "floating", Workspace: "ws1"},
+		}
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(floatingWindows, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindowsByWorkspace(constants.DefaultScratchpadWorkspaceName).
+			Return([]windows.Window{}, nil).
+			Times(1)
+
+		events, err := scratchpad.New(mockClient).List(
+			context.Background(),
+			scratchpad.ListOptions{Selector: scratchpad.Selector{Pattern: "^Spot"}},
+		)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(events) != 1 || events[0].AppName != "Spotify" {
+			t.Fatalf("expected only Spotify, got %+v", events)
+		}
+	})
+
+	t.Run("stops before talking to AeroSpace when context is done", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := scratchpad.New(mockClient).Show(ctx, scratchpad.ShowOptions{
+			Selector: scratchpad.Selector{Pattern: "Finder"},
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrTimeout and a timeout event when AeroSpace hangs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		release := make(chan struct{})
+		defer close(release)
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWorkspacesMock().EXPECT().
+			GetFocusedWorkspace().
+			DoAndReturn(func() (*workspaces.Workspace, error) {
+				<-release
+				return nil, nil
+			}).
+			Times(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		events, err := scratchpad.New(mockClient).Summon(ctx, scratchpad.SummonOptions{
+			Selector: scratchpad.Selector{Pattern: "Finder"},
+		})
+		if !errors.Is(err, scratchpad.ErrTimeout) {
+			t.Fatalf("expected ErrTimeout, got %v", err)
+		}
+
+		if len(events) != 1 || events[0].Action != "timeout" || events[0].Result != "error" {
+			t.Fatalf("expected a timeout event, got %+v", events)
+		}
+	})
+}
+
+func TestPerWorkspaceScratchpad(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Move stashes the window in the scratchpad of its workspace", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		window := windows.Window{AppName: "Notes", WindowID: 1, Workspace: "ws1"}
+		ownScratchpad := ".scratchpad-ws-ws1"
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(&window, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return([]windows.Window{window}, nil).
+			Times(1)
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(
+				workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: ownScratchpad},
+				workspaces.MoveWindowToWorkspaceOpts{WindowID: &window.WindowID},
+			).
+			Return(nil).
+			Times(1)
+		mockClient.GetLayoutMock().EXPECT().
+			SetLayout(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		sp := scratchpad.New(mockClient)
+		sp.SetOptions(scratchpad.Options{PerWorkspace: true})
+		events, err := sp.Move(ctx, scratchpad.MoveOptions{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// The focus moves away from the window before it is stashed
+		if len(events) != 2 || events[0].Action != "focus-next-tiling" ||
+			events[1].TargetWorkspace != ownScratchpad {
+			t.Fatalf("expected window stashed in %s, got %+v", ownScratchpad, events)
+		}
+	})
+
+	t.Run("Next prefers the windows stashed from the focused workspace", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		window := windows.Window{AppName: "Notes", WindowID: 1, Workspace: ".scratchpad-ws-ws1"}
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWorkspacesMock().EXPECT().
+			GetFocusedWorkspace().
+			Return(&workspaces.Workspace{Workspace: "ws1"}, nil).
+			Times(1)
+		// The shared scratchpad is never queried
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindowsByWorkspace(".scratchpad-ws-ws1").
+			Return([]windows.Window{window}, nil).
+			Times(1)
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+		mockClient.GetFocusMock().EXPECT().
+			SetFocusByWindowID(window.WindowID).
+			Return(nil).
+			Times(1)
+
+		sp := scratchpad.New(mockClient)
+		sp.SetOptions(scratchpad.Options{PerWorkspace: true})
+		events, err := sp.Next(ctx, scratchpad.NextOptions{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(events) != 1 || events[0].WindowID != window.WindowID {
+			t.Fatalf("expected window %d, got %+v", window.WindowID, events)
+		}
+	})
+
+	t.Run("List groups the windows by owning workspace", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		allWindows := []windows.Window{
+			{AppName: "Alpha", WindowID: 1, Workspace: ".scratchpad-ws-ws2"},
+			{AppName: "Beta", WindowID: 2, Workspace: ".scratchpad-ws-ws1"},
+			{AppName: "Gamma", WindowID: 3, Workspace: "ws1", WindowLayout: "floating"},
+			{AppName: "Delta", WindowID: 4, Workspace: constants.DefaultScratchpadWorkspaceName},
+		}
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(allWindows, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindowsByWorkspace(constants.DefaultScratchpadWorkspaceName).
+			Return(allWindows[3:], nil).
+			Times(1)
+
+		events, err := scratchpad.New(mockClient).List(
+			ctx,
+			scratchpad.ListOptions{GroupByWorkspace: true},
+		)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var order []int
+		for _, event := range events {
+			order = append(order, event.WindowID)
+		}
+		expected := []int{4, 2, 3, 1}
+		if !slices.Equal(order, expected) {
+			t.Fatalf("expected windows %v, got %v", expected, order)
+		}
+	})
+}
+
+func TestPools(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Move stashes the window in the pool workspace", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// Already in the shared scratchpad, moving it to a pool is allowed
+		window := windows.Window{
+			AppName:   "Spotify",
+			WindowID:  1,
+			Workspace: constants.DefaultScratchpadWorkspaceName,
+		}
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return([]windows.Window{window}, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(&windows.Window{AppName: "Finder", WindowID: 2, Workspace: "ws1"}, nil).
+			Times(1)
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(
+				workspaces.MoveWindowToWorkspaceArgs{WorkspaceName: ".scratchpad-pool-media"},
+				workspaces.MoveWindowToWorkspaceOpts{WindowID: &window.WindowID},
+			).
+			Return(nil).
+			Times(1)
+		mockClient.GetLayoutMock().EXPECT().
+			SetLayout(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		events, err := scratchpad.New(mockClient).Move(ctx, scratchpad.MoveOptions{
+			Selector:    scratchpad.Selector{Pattern: "Spotify", Pool: "media"},
+			AllMatching: true,
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(events) != 1 ||
+			events[0].TargetWorkspace != ".scratchpad-pool-media" ||
+			events[0].Pool != "media" {
+			t.Fatalf("expected window stashed in pool media, got %+v", events)
+		}
+	})
+
+	t.Run("List keeps pools apart from per-workspace scratchpads", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// The pool `1` and the scratchpad of workspace `1`
+		allWindows := []windows.Window{
+			{AppName: "Alpha", WindowID: 1, Workspace: ".scratchpad-ws-1"},
+			{AppName: "Beta", WindowID: 2, Workspace: ".scratchpad-pool-1"},
+			{AppName: "Gamma", WindowID: 3, Workspace: constants.DefaultScratchpadWorkspaceName},
+		}
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return(allWindows, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindowsByWorkspace(constants.DefaultScratchpadWorkspaceName).
+			Return(allWindows[2:], nil).
+			Times(1)
+
+		events, err := scratchpad.New(mockClient).List(
+			ctx,
+			scratchpad.ListOptions{GroupByWorkspace: true},
+		)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var order []int
+		pools := map[int]string{}
+		for _, event := range events {
+			order = append(order, event.WindowID)
+			pools[event.WindowID] = event.Pool
+		}
+		// The pool has no owning workspace, it goes with the shared scratchpad
+		if expected := []int{2, 3, 1}; !slices.Equal(order, expected) {
+			t.Fatalf("expected windows %v, got %v", expected, order)
+		}
+		if expected := map[int]string{1: "", 2: "1", 3: ""}; !maps.Equal(pools, expected) {
+			t.Fatalf("expected pools %v, got %v", expected, pools)
+		}
+	})
+
+	t.Run("Next only takes windows from the pool", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWorkspacesMock().EXPECT().
+			GetFocusedWorkspace().
+			Return(&workspaces.Workspace{Workspace: "ws1"}, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindowsByWorkspace(".scratchpad-pool-media").
+			Return([]windows.Window{}, nil).
+			Times(1)
+
+		_, err := scratchpad.New(mockClient).Next(ctx, scratchpad.NextOptions{Pool: "media"})
+		if err == nil || err.Error() != "no scratchpad windows found" {
+			t.Fatalf("expected no scratchpad windows error, got %v", err)
+		}
+	})
+
+	t.Run("rejects pool names unusable in a workspace name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+
+		_, err := scratchpad.New(mockClient).Show(ctx, scratchpad.ShowOptions{
+			Selector: scratchpad.Selector{
+				Pattern: "Spotify",
+				Pool:    "my media",
+			},
+		})
+		if err == nil {
+			t.Fatalf("expected invalid pool error")
+		}
+	})
+}
+
+func TestSticky(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Show records the windows it brings as sticky", func(t *testing.T) {
+		t.Setenv("TMPDIR", t.TempDir())
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		window := windows.Window{
+			AppName:      "Terminal",
+			WindowID:     1,
+			Workspace:    constants.DefaultScratchpadWorkspaceName,
+			WindowLayout: "floating",
+		}
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWorkspacesMock().EXPECT().
+			GetFocusedWorkspace().
+			Return(&workspaces.Workspace{Workspace: "ws1"}, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return([]windows.Window{window}, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(&windows.Window{WindowID: 2, Workspace: "ws1"}, nil).
+			Times(1)
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+		mockClient.GetFocusMock().EXPECT().
+			SetFocusByWindowID(gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		sp := scratchpad.New(mockClient)
+		sp.SetOptions(scratchpad.Options{Sticky: true})
+		if _, err := sp.Show(ctx, scratchpad.ShowOptions{
+			Selector: scratchpad.Selector{Pattern: "Terminal"},
+		}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		current, err := state.New().Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(current.Sticky, []int{1}) {
+			t.Fatalf("expected window 1 to be sticky, got %v", current.Sticky)
+		}
+		if current.PreviousFocus[1] != 2 {
+			t.Fatalf("expected window 2 to be focused again on hide, got %v", current.PreviousFocus)
+		}
+		if current.ShownFrom[1] != constants.DefaultScratchpadWorkspaceName {
+			t.Fatalf("expected window 1 to be shown from the scratchpad, got %v", current.ShownFrom)
+		}
+	})
+
+	t.Run("Show writes no state without the option", func(t *testing.T) {
+		t.Setenv("TMPDIR", t.TempDir())
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		window := windows.Window{
+			AppName:      "Terminal",
+			WindowID:     1,
+			Workspace:    constants.DefaultScratchpadWorkspaceName,
+			WindowLayout: "floating",
+		}
+
+		mockClient := testutils.NewMockAeroSpaceWM(ctrl)
+		mockClient.GetWorkspacesMock().EXPECT().
+			GetFocusedWorkspace().
+			Return(&workspaces.Workspace{Workspace: "ws1"}, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetAllWindows().
+			Return([]windows.Window{window}, nil).
+			Times(1)
+		mockClient.GetWindowsMock().EXPECT().
+			GetFocusedWindow().
+			Return(&windows.Window{WindowID: 2, Workspace: "ws1"}, nil).
+			Times(1)
+		mockClient.GetWorkspacesMock().EXPECT().
+			MoveWindowToWorkspaceWithOpts(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+		mockClient.GetFocusMock().EXPECT().
+			SetFocusByWindowID(gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		sp := scratchpad.New(mockClient)
+		if _, err := sp.Show(ctx, scratchpad.ShowOptions{
+			Selector: scratchpad.Selector{Pattern: "Terminal"},
+		}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err := os.Stat(state.New().Path()); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("expected no state file, got %v", err)
+		}
+	})
+}
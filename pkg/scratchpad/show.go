@@ -0,0 +1,161 @@
+package scratchpad
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	windowsipc "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+)
+
+// Show toggles the windows matching the pattern between the scratchpad
+// and the focused workspace.
+//
+// Windows outside the focused workspace are brought to it. When they are all
+// there already, the matched windows are hidden if one of them is focused,
+// otherwise they get focused.
+//
+// With a pool, windows stashed in other scratchpads are ignored and hidden
+// windows go to the pool. In per-workspace mode the windows stashed from the
+// focused workspace are preferred over the ones stashed from other
+// workspaces.
+//
+// With ShowOptions.Target the windows are toggled in another workspace
+// instead of the focused one.
+//
+// With Options.Sticky the windows brought to the focused workspace are
+// recorded as sticky, see `hook follow`. The toggle itself is planned by
+// aerospace.PlanToggle, following ShowOptions.Policy.
+func (s *Scratchpad) Show(
+	ctx context.Context,
+	opts ShowOptions,
+) (events []Event, err error) {
+	start := time.Now()
+	// Resolved below, the monitor is reported by the events
+	var targetWorkspace *workspaces.Workspace
+	var monitor string
+	defer func() {
+		// Windows staged on another workspace stay there
+		if opts.Target.IsFocused() {
+			s.updateSticky(events)
+		}
+		events = setMonitor(s.finish("show", start, events, err), targetWorkspace, monitor)
+	}()
+
+	if err = checkContext(ctx); err != nil {
+		return nil, err
+	}
+	if err = ValidatePool(opts.Pool); err != nil {
+		return nil, err
+	}
+	if err = opts.Target.Validate(); err != nil {
+		return nil, err
+	}
+	if err = opts.Policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	aerospaceClient := s.client
+	logger := logger.GetDefaultLogger()
+	logger.LogDebug("SHOW: start command", "opts", opts)
+	windowNamePattern := strings.TrimSpace(opts.Pattern)
+	if windowNamePattern == "" {
+		return nil, errors.New("<pattern> cannot be empty")
+	}
+
+	targetWorkspace, monitor, err = s.targetWorkspace(ctx, opts.Target)
+	if err != nil {
+		logger.LogError(
+			"SHOW: unable to get target workspace",
+			"error",
+			err,
+		)
+		return nil, err
+	}
+	logger.LogDebug(
+		"SHOW: retrieved target workspace",
+		"workspace",
+		targetWorkspace,
+	)
+
+	setFocus := s.focusesTarget(ctx, opts.Target)
+
+	querier := aerospace.NewAerospaceQuerier(aerospaceClient)
+	mover := s.newMover(opts.Pool)
+
+	windows, err := querier.GetFilteredWindows(
+		ctx,
+		windowNamePattern,
+		opts.Filters,
+	)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case opts.Pool != "":
+		windows = withoutOtherScratchpads(windows, aerospace.PoolWorkspace(opts.Pool))
+	case s.perWorkspace:
+		windows = preferOwnScratchpad(windows, targetWorkspace.Workspace)
+	}
+
+	snapshot, err := toggleSnapshot(ctx, querier, windows, targetWorkspace.Workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	restoresFocus := opts.Policy.AfterHide == "" ||
+		opts.Policy.AfterHide == aerospace.AfterHidePrevious
+	if restoresFocus {
+		snapshot.PreviousFocus = loadPreviousFocus()
+	}
+
+	action, steps := aerospace.PlanToggle(snapshot, windows, opts.Policy)
+	if !setFocus {
+		steps = withoutFocus(steps)
+	}
+	logger.LogDebug("SHOW: planned toggle", "action", action, "steps", steps)
+
+	previous := 0
+	if restoresFocus && setFocus && action == aerospace.ToggleBring && snapshot.FocusedWindowID == 0 {
+		previous = s.focusedWindowID(ctx)
+	}
+	defer func() {
+		s.updatePreviousFocus(previous, events)
+	}()
+
+	return s.runToggle(ctx, "show", &mover, targetWorkspace, steps)
+}
+
+// preferOwnScratchpad drops the windows stashed from other workspaces when
+// some are stashed from the focused one. Windows outside any scratchpad, and
+// all of them when the focused workspace has none stashed, are kept.
+func preferOwnScratchpad(
+	windows []windowsipc.Window,
+	focusedWorkspace string,
+) []windowsipc.Window {
+	ownScratchpad := aerospace.WorkspaceScratchpad(focusedWorkspace)
+	if !slices.ContainsFunc(windows, func(window windowsipc.Window) bool {
+		return window.Workspace == ownScratchpad
+	}) {
+		return windows
+	}
+
+	return withoutOtherScratchpads(windows, ownScratchpad)
+}
+
+// withoutOtherScratchpads drops the windows stashed in a scratchpad other
+// than the given one.
+func withoutOtherScratchpads(
+	windows []windowsipc.Window,
+	scratchpad string,
+) []windowsipc.Window {
+	return slices.DeleteFunc(slices.Clone(windows), func(window windowsipc.Window) bool {
+		return window.Workspace != scratchpad &&
+			aerospace.IsScratchpadWorkspace(window.Workspace)
+	})
+}
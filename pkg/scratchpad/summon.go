@@ -0,0 +1,187 @@
+package scratchpad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+)
+
+// Summon brings the windows matching the pattern to the focused workspace.
+// With a pool, windows stashed in other scratchpads are ignored. With
+// SummonOptions.Target the windows are brought to another workspace.
+//
+// With SummonOptions.Toggle the windows are hidden instead when Show would
+// hide them: all in the focused workspace and one of them focused. The focus
+// then goes back to the window focused before the summon, as with Show.
+//
+//nolint:funlen // summon flow keeps the skip handling inline
+func (s *Scratchpad) Summon(
+	ctx context.Context,
+	opts SummonOptions,
+) (events []Event, err error) {
+	start := time.Now()
+	// Resolved below, the monitor is reported by the events
+	var targetWorkspace *workspaces.Workspace
+	var monitor string
+	defer func() {
+		events = setMonitor(s.finish("summon", start, events, err), targetWorkspace, monitor)
+	}()
+
+	if err = checkContext(ctx); err != nil {
+		return nil, err
+	}
+	if err = ValidatePool(opts.Pool); err != nil {
+		return nil, err
+	}
+	if err = opts.Target.Validate(); err != nil {
+		return nil, err
+	}
+
+	aerospaceClient := s.client
+	logger := logger.GetDefaultLogger()
+	windowNamePattern := strings.TrimSpace(opts.Pattern)
+
+	targetWorkspace, monitor, err = s.targetWorkspace(ctx, opts.Target)
+	if err != nil {
+		logger.LogError(
+			"SUMMON: unable to get target workspace",
+			"error",
+			err,
+		)
+		return nil, err
+	}
+
+	setFocus := s.focusesTarget(ctx, opts.Target)
+
+	// Filter windows using the shared querier
+	querier := aerospace.NewAerospaceQuerier(aerospaceClient)
+	mover := s.newMover(opts.Pool)
+
+	windows, err := querier.GetFilteredWindows(
+		ctx,
+		windowNamePattern,
+		opts.Filters,
+	)
+	if err != nil {
+		logger.LogError(
+			"SUMMON: unable to get filtered windows",
+			"error",
+			err,
+		)
+		return nil, err
+	}
+	if opts.Pool != "" {
+		windows = withoutOtherScratchpads(windows, aerospace.PoolWorkspace(opts.Pool))
+	}
+
+	previous := 0
+	defer func() {
+		s.updatePreviousFocus(previous, events)
+	}()
+
+	if opts.Toggle {
+		snapshot, snapshotErr := toggleSnapshot(ctx, querier, windows, targetWorkspace.Workspace)
+		if snapshotErr != nil {
+			return nil, snapshotErr
+		}
+		// The default policy hides to the window focused before, as Show
+		snapshot.PreviousFocus = loadPreviousFocus()
+		action, steps := aerospace.PlanToggle(snapshot, windows, aerospace.TogglePolicy{})
+		if action == aerospace.ToggleHide {
+			return s.runToggle(ctx, "summon", &mover, targetWorkspace, steps)
+		}
+		if setFocus && snapshot.FocusedWindowID == 0 {
+			previous = s.focusedWindowID(ctx)
+		}
+	}
+
+	for _, window := range windows {
+		if err = checkContext(ctx); err != nil {
+			return events, err
+		}
+
+		moveErr := mover.MoveWindowToWorkspace(
+			ctx,
+			&window,
+			targetWorkspace,
+			setFocus,
+		)
+		if moveErr != nil {
+			if errors.Is(moveErr, aerospace.ErrAlreadyInWorkspace) {
+				logger.LogDebug(
+					"SUMMON: window already belongs to workspace",
+					"window",
+					window,
+					"workspace",
+					targetWorkspace,
+					"error",
+					moveErr,
+				)
+				// Only focused when visible, like the windows it moves
+				if setFocus {
+					if focusErr := aerospaceClient.SetFocusByWindowID(ctx, window.WindowID); focusErr != nil {
+						logger.LogError(
+							"SUMMON: unable to set focus to window",
+							"window",
+							window,
+							"error",
+							focusErr,
+						)
+						return events, fmt.Errorf(
+							"unable to set focus to window '%+v'\n%w",
+							window,
+							focusErr,
+						)
+					}
+				}
+
+				events = append(events, Event{
+					Command:         "summon",
+					Action:          "to-workspace",
+					Workspace:       window.Workspace,
+					TargetWorkspace: targetWorkspace.Workspace,
+					Result:          "skipped",
+					Message:         "already in target workspace",
+				}.WithWindow(window))
+				continue
+			}
+
+			logger.LogDebug(
+				"SUMMON: unable to move window to workspace",
+				"window",
+				window,
+				"workspace",
+				targetWorkspace,
+				"error",
+				moveErr,
+			)
+			if s.atomic {
+				events = append(events, s.rollback(ctx, &mover, Event{
+					Command:         "summon",
+					Action:          "to-workspace",
+					Workspace:       window.Workspace,
+					TargetWorkspace: targetWorkspace.Workspace,
+					Result:          "error",
+					Message:         moveErr.Error(),
+				}.WithWindow(window))...)
+			}
+			return events, moveErr
+		}
+
+		events = append(events, Event{
+			Command:         "summon",
+			Action:          "to-workspace",
+			Workspace:       window.Workspace,
+			TargetWorkspace: targetWorkspace.Workspace,
+			Result:          "ok",
+		}.WithWindow(window))
+	}
+
+	return events, nil
+}
@@ -0,0 +1,127 @@
+package scratchpad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+)
+
+// Target selects the workspace Show, Summon and Next bring the windows to.
+// The zero value targets the focused workspace. The windows brought to a
+// workspace that is not visible are not focused, so the focus stays put.
+type Target struct {
+	// Workspace is the name of the workspace to bring the windows to
+	Workspace string
+	// Monitor brings the windows to the workspace visible on a monitor:
+	// "focused", "mouse" or a monitor ID as listed by `aerospace list-monitors`
+	Monitor string
+}
+
+// IsFocused reports whether the target is the focused workspace.
+func (t Target) IsFocused() bool {
+	return t.Workspace == "" && t.Monitor == ""
+}
+
+// Validate makes sure at most one of workspace and monitor is set, and that
+// it can be targeted.
+func (t Target) Validate() error {
+	switch {
+	case t.Workspace != "" && t.Monitor != "":
+		return errors.New("target either a workspace or a monitor, not both")
+	case aerospace.IsScratchpadWorkspace(t.Workspace):
+		return fmt.Errorf("invalid target workspace '%s': it is a scratchpad", t.Workspace)
+	case t.Monitor != "":
+		return aerospace.ValidateMonitor(t.Monitor)
+	default:
+		return nil
+	}
+}
+
+// targetWorkspace resolves the workspace the windows are brought to, along
+// with the name of the monitor it was resolved from.
+func (s *Scratchpad) targetWorkspace(
+	ctx context.Context,
+	target Target,
+) (*workspaces.Workspace, string, error) {
+	switch {
+	case target.Workspace != "":
+		return &workspaces.Workspace{Workspace: target.Workspace}, "", nil
+	case target.Monitor != "":
+		monitor, err := s.client.GetMonitor(ctx, target.Monitor)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to get monitor '%s': %w", target.Monitor, err)
+		}
+		// By ID, the mouse may have moved to another monitor meanwhile
+		workspace, err := s.client.GetVisibleWorkspace(ctx, strconv.Itoa(monitor.ID))
+		if err != nil {
+			return nil, "", fmt.Errorf(
+				"unable to get workspace of monitor '%s': %w",
+				monitor.Name,
+				err,
+			)
+		}
+		return workspace, monitor.Name, nil
+	default:
+		workspace, err := s.client.GetFocusedWorkspace(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to get focused workspace: %w", err)
+		}
+		return workspace, "", nil
+	}
+}
+
+// focusesTarget reports whether the windows brought to the target get the
+// focus. Focusing a window switches to its workspace, so the windows brought
+// to a workspace that is not visible are left unfocused.
+func (s *Scratchpad) focusesTarget(ctx context.Context, target Target) bool {
+	if target.Workspace == "" {
+		// The focused workspace or the one visible on a monitor
+		return true
+	}
+
+	visible, err := s.client.IsWorkspaceVisible(ctx, target.Workspace)
+	if err != nil {
+		logger.GetDefaultLogger().LogError(
+			"TARGET: unable to check if the workspace is visible",
+			"workspace", target.Workspace,
+			"error", err,
+		)
+		return false
+	}
+	return visible
+}
+
+// withoutFocus drops the focus changes from the steps of a toggle, for the
+// targets that are not visible.
+func withoutFocus(steps []aerospace.ToggleStep) []aerospace.ToggleStep {
+	kept := make([]aerospace.ToggleStep, 0, len(steps))
+	for _, step := range steps {
+		switch step.Action {
+		case aerospace.StepFocus, aerospace.StepRefocusPrevious, aerospace.StepRefocusTiling:
+			continue
+		}
+		step.SetFocus = false
+		kept = append(kept, step)
+	}
+	return kept
+}
+
+// setMonitor fills the monitor of the events bringing windows to the
+// workspace resolved from it.
+func setMonitor(events []Event, workspace *workspaces.Workspace, monitor string) []Event {
+	if workspace == nil || monitor == "" {
+		return events
+	}
+
+	for i, event := range events {
+		if event.TargetWorkspace == workspace.Workspace {
+			events[i].Monitor = monitor
+		}
+	}
+	return events
+}
@@ -0,0 +1,268 @@
+package scratchpad
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	windowsipc "github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/windows"
+	"github.com/cristianoliveira/aerospace-ipc/pkg/aerospace/workspaces"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/aerospace"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/logger"
+	"github.com/cristianoliveira/aerospace-scratchpad/internal/state"
+)
+
+// toggleSnapshot captures what planning a toggle of the matched windows
+// depends on. Only the windows in the focused workspace are checked for
+// focus.
+func toggleSnapshot(
+	ctx context.Context,
+	querier aerospace.Querier,
+	windows []windowsipc.Window,
+	focusedWorkspace string,
+) (aerospace.Snapshot, error) {
+	snapshot := aerospace.Snapshot{FocusedWorkspace: focusedWorkspace}
+	for _, window := range windows {
+		if window.Workspace != focusedWorkspace {
+			continue
+		}
+
+		focused, err := querier.IsWindowFocused(ctx, window.WindowID)
+		if err != nil {
+			return snapshot, fmt.Errorf(
+				"unable to check if window '%+v' is focused: %w",
+				window,
+				err,
+			)
+		}
+		if focused {
+			snapshot.FocusedWindowID = window.WindowID
+		}
+	}
+
+	logger.GetDefaultLogger().LogDebug("TOGGLE: snapshot", "snapshot", snapshot)
+
+	return snapshot, nil
+}
+
+// runToggle executes the steps of a toggle plan in order. A window failing
+// to hide is reported and the others are still hidden, unless in atomic
+// mode. Any other failure stops the run.
+//
+//nolint:funlen // one case per step action
+func (s *Scratchpad) runToggle(
+	ctx context.Context,
+	command string,
+	mover *aerospace.MoverAeroSpace,
+	focusedWorkspace *workspaces.Workspace,
+	steps []aerospace.ToggleStep,
+) ([]Event, error) {
+	logger := logger.GetDefaultLogger()
+
+	var events []Event
+	for _, step := range steps {
+		window := step.Window
+		logger.LogDebug("TOGGLE: run step", "step", step)
+
+		switch step.Action {
+		// Show only reports the windows it moves and focuses on purpose, the
+		// focus moving away from the hidden windows is left out.
+		case aerospace.StepRefocusTiling:
+			s.focusNextTiling(ctx, command)
+
+		case aerospace.StepRefocusPrevious:
+			s.restoreFocus(ctx, command, window)
+
+		case aerospace.StepBring:
+			event := Event{
+				Command:         command,
+				Action:          "to-workspace",
+				Workspace:       window.Workspace,
+				TargetWorkspace: focusedWorkspace.Workspace,
+				Result:          "ok",
+			}.WithWindow(window)
+			moveErr := mover.MoveWindowToWorkspace(ctx, &window, focusedWorkspace, step.SetFocus)
+			if moveErr != nil {
+				if s.atomic {
+					event.Result = "error"
+					event.Message = moveErr.Error()
+					events = append(events, s.rollback(ctx, mover, event)...)
+				}
+				return events, moveErr
+			}
+			events = append(events, event)
+
+		case aerospace.StepHide:
+			event := Event{
+				Command:         command,
+				Action:          "to-scratchpad",
+				Workspace:       window.Workspace,
+				TargetWorkspace: mover.ScratchpadFor(window),
+				Result:          "ok",
+			}.WithWindow(window)
+			if moveErr := mover.MoveWindowToScratchpad(ctx, window); moveErr != nil {
+				logger.LogDebug(
+					"TOGGLE: unable to move window to scratchpad",
+					"window",
+					window,
+					"error",
+					moveErr,
+				)
+				event.Result = "error"
+				event.Message = moveErr.Error()
+				if s.atomic {
+					return append(events, s.rollback(ctx, mover, event)...), moveErr
+				}
+			}
+			events = append(events, event)
+
+		case aerospace.StepFocus:
+			if err := s.client.SetFocusByWindowID(ctx, window.WindowID); err != nil {
+				return events, fmt.Errorf(
+					"unable to set focus to window '%+v'\n%w",
+					window,
+					err,
+				)
+			}
+			events = append(events, Event{
+				Command:   command,
+				Action:    "focus",
+				Workspace: window.Workspace,
+				Result:    "ok",
+			}.WithWindow(window))
+		}
+	}
+
+	return events, nil
+}
+
+// loadPreviousFocus returns the windows focused before Show brought
+// windows, by brought window.
+func loadPreviousFocus() map[int]int {
+	st, err := state.New().Load()
+	if err != nil {
+		logger.GetDefaultLogger().LogError("STATE: unable to load previous focus", "error", err)
+	}
+	return st.PreviousFocus
+}
+
+// focusedWindowID returns the focused window, 0 when none is or it cannot be
+// told.
+func (s *Scratchpad) focusedWindowID(ctx context.Context) int {
+	focused, err := s.client.GetFocusedWindow(ctx)
+	if err != nil || focused == nil {
+		logger.GetDefaultLogger().LogDebug("TOGGLE: no focused window to restore", "error", err)
+		return 0
+	}
+	return focused.WindowID
+}
+
+// updatePreviousFocus records the previous window of the windows brought by
+// the events, and forgets the one of the hidden windows, in sticky mode only.
+// Failing to do so only falls back to the next tiling window, it never fails
+// the operation.
+func (s *Scratchpad) updatePreviousFocus(previous int, events []Event) {
+	if !s.sticky || s.client.IsDryRun() || !slices.ContainsFunc(events, func(event Event) bool {
+		return event.Result == "ok" &&
+			(event.Action == "to-scratchpad" || event.Action == "to-workspace" && previous != 0)
+	}) {
+		return
+	}
+
+	err := state.New().Update(func(st *state.State) {
+		for _, event := range events {
+			if event.Result != "ok" {
+				continue
+			}
+			switch event.Action {
+			case "to-workspace":
+				if previous != 0 {
+					st.SetPreviousFocus(event.WindowID, previous)
+				}
+			case "to-scratchpad":
+				st.ForgetPreviousFocus(event.WindowID)
+			}
+		}
+	})
+	if err != nil {
+		logger.GetDefaultLogger().LogError("STATE: unable to update previous focus", "error", err)
+	}
+}
+
+// refocusAfterHide moves the focus away from the windows about to be hidden,
+// to the window focused before they were shown when known. The focus change
+// is reported as an event of the command.
+func (s *Scratchpad) refocusAfterHide(
+	ctx context.Context,
+	command string,
+	hidden []windowsipc.Window,
+) Event {
+	if previous, ok := aerospace.PreviousFocus(loadPreviousFocus(), hidden); ok {
+		return s.restoreFocus(ctx, command, previous)
+	}
+
+	return s.focusNextTiling(ctx, command)
+}
+
+// restoreFocus focuses the previous window, or the next tiling window when
+// it no longer exists in the workspace it was left in.
+func (s *Scratchpad) restoreFocus(
+	ctx context.Context,
+	command string,
+	previous windowsipc.Window,
+) Event {
+	logger := logger.GetDefaultLogger()
+
+	workspaceWindows, err := s.client.GetAllWindowsByWorkspace(ctx, previous.Workspace)
+	if err != nil {
+		logger.LogError("TOGGLE: unable to get windows of the workspace", "error", err)
+	}
+	index := slices.IndexFunc(workspaceWindows, func(window windowsipc.Window) bool {
+		return window.WindowID == previous.WindowID
+	})
+	if index == -1 {
+		logger.LogDebug("TOGGLE: previous window is gone, focusing next tiling window", "window", previous)
+		return s.focusNextTiling(ctx, command)
+	}
+
+	window := workspaceWindows[index]
+	if err = s.client.SetFocusByWindowID(ctx, window.WindowID); err != nil {
+		logger.LogDebug(
+			"TOGGLE: unable to focus previous window, focusing next tiling window",
+			"window",
+			window,
+			"error",
+			err,
+		)
+		return s.focusNextTiling(ctx, command)
+	}
+
+	return Event{
+		Command:   command,
+		Action:    "focus",
+		Workspace: window.Workspace,
+		Result:    "ok",
+	}.WithWindow(window)
+}
+
+// focusNextTiling focuses the next tiling window, or the previous one when
+// there is no next.
+func (s *Scratchpad) focusNextTiling(ctx context.Context, command string) Event {
+	event := Event{
+		Command: command,
+		Action:  "focus-next-tiling",
+		Result:  "ok",
+	}
+	if err := s.client.FocusNextTilingWindow(ctx); err != nil {
+		// No need to exit here, just log the error and continue
+		logger.GetDefaultLogger().LogError(
+			"TOGGLE: unable to focus next tiling window",
+			"error",
+			err,
+		)
+		event.Result = "error"
+		event.Message = err.Error()
+	}
+
+	return event
+}